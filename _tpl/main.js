@@ -6,9 +6,11 @@ const CLEAR_QUERY = 'CLEAR_QUERY';
 const FETCH_QUERY_RESULT = 'FETCH_QUERY_RESULT ';
 const SET_QUERY_TERM = 'SET_QUERY';
 const SET_QUERY_RESULT = 'SET_QUERY_RESULT';
+const APPEND_QUERY_RESULT = 'APPEND_QUERY_RESULT';
 const SET_FILE = 'SET_FILE';
 const SET_FILE_CONTENT = 'SET_FILE_CONTENT';
 const INITIAL_QUERY = { isQuerying: false, result: {} };
+const PAGE_SIZE = 18;
 
 function queryReducer(state = INITIAL_QUERY, action) {
     switch (action.type) {
@@ -24,6 +26,12 @@ function queryReducer(state = INITIAL_QUERY, action) {
         case SET_QUERY_RESULT:
             return Object.assign({}, state, { result: action.value, isQuerying: false });
 
+        case APPEND_QUERY_RESULT: {
+            let docs = (state.result.Docs || []).concat(action.value.Docs || []);
+            let result = Object.assign({}, state.result, { Docs: docs, Next: action.value.Next, TookMs: action.value.TookMs });
+            return Object.assign({}, state, { result, isQuerying: false });
+        }
+
         default:
             return state;
     }
@@ -70,16 +78,115 @@ let FileList = {
             let filename = d.Document;
             let key = filename;
             let label = toLabel(filename);
-            return m(FileItem, {dispatch, filename, key, label})
+            let snippets = d.Snippets || [];
+            return m(FileItem, {dispatch, filename, key, label, snippets})
         });
     }
 }
 
 let FileItem = {
     view: function (vnode) {
-        let {dispatch, filename, label} = vnode.attrs;
-        return m("li", {class: "autocomplete-item", onclick: e => dispatch(setFile(filename))},
-            label);
+        let {dispatch, filename, label, snippets} = vnode.attrs;
+        return m("li", {class: "autocomplete-item", onclick: e => dispatch(setFile(filename))}, [
+            label,
+            snippets.length > 0 ? m(Snippets, {snippets}) : null,
+        ]);
+    }
+}
+
+let Snippets = {
+    view: function (vnode) {
+        let {snippets} = vnode.attrs;
+        return m("ol", {class: "snippets"}, snippets.map(s =>
+            m("li", {key: s.Line}, [
+                m("span", {class: "line-number"}, s.Line),
+                m("code", highlight(s)),
+            ])
+        ));
+    }
+}
+
+// highlight marks the matched words of a snippet, the offsets count UTF-8 bytes.
+const encoder = new TextEncoder();
+const decoder = new TextDecoder();
+
+function highlight(snippet) {
+    let bytes = encoder.encode(snippet.Text);
+    let parts = [];
+    let pos = 0;
+    for (let [start, end] of snippet.Highlights || []) {
+        parts.push(decoder.decode(bytes.slice(pos, start)));
+        parts.push(m("mark", decoder.decode(bytes.slice(start, end))));
+        pos = end;
+    }
+    parts.push(decoder.decode(bytes.slice(pos)));
+    return parts;
+}
+
+// utf16Offset converts a UTF-8 byte offset in text, as reported by the server, to a string index.
+function utf16Offset(text, pos) {
+    return decoder.decode(encoder.encode(text).slice(0, pos)).length;
+}
+
+let Summary = {
+    view: function (vnode) {
+        let {shown, total, tookMs} = vnode.attrs;
+        return m("li", {class: "autocomplete-item summary"},
+            m("small", shown + " of " + total + " documents in " + tookMs + " ms"));
+    }
+}
+
+let MoreResults = {
+    view: function (vnode) {
+        let {more} = vnode.attrs;
+        return m("li", {class: "autocomplete-item more", onclick: e => more()}, "More results");
+    }
+}
+
+let QueryError = {
+    view: function (vnode) {
+        let {term, error} = vnode.attrs;
+        if (error.Param) {
+            return m("li", {class: "autocomplete-item query-error"}, error.Message);
+        }
+        let pos = utf16Offset(term, error.Pos);
+        let mark = String.fromCodePoint(term.codePointAt(pos) || 32);
+        return m("li", {class: "autocomplete-item query-error"}, [
+            m("code", [
+                term.slice(0, pos),
+                m("mark", mark),
+                term.slice(pos + mark.length),
+            ]),
+            " " + error.Message,
+        ]);
+    }
+}
+
+let Expansions = {
+    view: function (vnode) {
+        let {expansions} = vnode.attrs;
+        let terms = expansions.map(x => {
+            let words = x.Words == null || x.Words.length === 0
+                ? 'no matches'
+                : x.Words.map(w => w.Word).join(', ');
+            return x.Term + ' → ' + words;
+        });
+        return m("li", {class: "autocomplete-item expansions"},
+            m("small", "Showing results for " + terms.join('; ')));
+    }
+}
+
+let Definitions = {
+    view: function (vnode) {
+        let {definitions, dispatch} = vnode.attrs;
+        return definitions.map(d => {
+            let name = d.Receiver ? d.Receiver + '.' + d.Name : d.Name;
+            let key = d.Document + ':' + d.Line;
+            return m("li", {key, class: "autocomplete-item definition", onclick: e => dispatch(setFile(d.Document))}, [
+                m("code", name),
+                " " + d.Kind + " " + toLabel(d.Document) + ":" + d.Line,
+            ]);
+        });
     }
 }
 
@@ -125,6 +232,13 @@ function setQueryResult(value) {
     };
 }
 
+function appendQueryResult(value) {
+    return {
+        type: APPEND_QUERY_RESULT,
+        value
+    };
+}
+
 function clearQuery() {
     return {
         type: CLEAR_QUERY,
@@ -160,19 +274,40 @@ function regSub(store, path, fn) {
     });
 }
 
-function renderFileList(el, dispatch) {
+function renderFileList(el, dispatch, fetchMore) {
     return function(json) {
+        if (json.Error != null) {
+            m.render(el, m(QueryError, {term: json.term || '', error: json.Error}));
+            return;
+        }
         let docs = json.Docs;
         if (json.Docs == null) {
             docs = [];
         }
-        if (docs.length > 18) {
-            docs = docs.slice(0, 18);
-        }
-        m.render(el, m(FileList, {dispatch, docs}));
+        let expansions = json.Expansions || [];
+        let definitions = json.Definitions || [];
+        let more = () => fetchMore(json.term, json.Next);
+        m.render(el, [
+            expansions.length > 0 ? m(Expansions, {expansions}) : null,
+            json.term != null && definitions.length === 0 ? m(Summary, {shown: docs.length, total: json.Total, tookMs: json.TookMs}) : null,
+            definitions.length > 0 ? m(Definitions, {definitions, dispatch}) : m(FileList, {dispatch, docs}),
+            json.Next && definitions.length === 0 ? m(MoreResults, {more}) : null,
+        ]);
     }
 }
 
+function renderCompletions(el) {
+    return function({term, words}) {
+        let lead = term.slice(0, term.length - lastWord(term).length);
+        m.render(el, words.map(w => m("option", {key: w, value: lead + w})));
+    }
+}
+
+function lastWord(term) {
+    let match = term.match(/[A-Za-z0-9_'-]+$/);
+    return match == null ? '' : match[0];
+}
+
 function renderBreadcrumbs(el) {
     return function(filename) {
         if (filename == null || filename === '') {
@@ -206,7 +341,7 @@ function renderCode(el) {
     }
 }
 
-function Exec(breadcrumbs, code, files, search, searchSpinner) {
+function Exec(breadcrumbs, code, files, search, searchSpinner, completions) {
 
     let rootReducer = Redux.combineReducers({
         query: queryReducer,
@@ -219,9 +354,24 @@ function Exec(breadcrumbs, code, files, search, searchSpinner) {
     let query = (v) => {
         if (v == null) return;
         store.dispatch(fetchQueryResult());
-        fetch('/search?q='+encodeURIComponent(v))
+        fetch('/search?limit='+PAGE_SIZE+'&q='+encodeURIComponent(v))
+            .then(response => response.json())
+            .then(json => store.dispatch(setQueryResult(Object.assign({term: v}, json)))) };
+    let queryMore = (v, cursor) => {
+        store.dispatch(fetchQueryResult());
+        fetch('/search?limit='+PAGE_SIZE+'&q='+encodeURIComponent(v)+'&cursor='+encodeURIComponent(cursor))
+            .then(response => response.json())
+            .then(json => store.dispatch(appendQueryResult(json))) };
+    let complete = (v) => {
+        if (v == null) return;
+        let prefix = lastWord(v);
+        if (prefix.length < 2) {
+            renderCompletions(completions)({term: v, words: []});
+            return;
+        }
+        fetch('/complete?q='+encodeURIComponent(prefix))
             .then(response => response.json())
-            .then(json => store.dispatch(setQueryResult(json))) };
+            .then(json => renderCompletions(completions)({term: v, words: json.Words || []})) };
     let fetchFile = (v) => {
         if (v == null) return;
         fetch('/files/'+v)
@@ -256,8 +406,9 @@ function Exec(breadcrumbs, code, files, search, searchSpinner) {
     regSub(store, ['file', 'name'], fetchFile);
     regSub(store, ['file', 'name'], setLocationHash)
     regSub(store, ['query', 'isQuerying'], renderQueryState(searchSpinner));
-    regSub(store, ['query', 'result'], renderFileList(files, store.dispatch));
+    regSub(store, ['query', 'result'], renderFileList(files, store.dispatch, queryMore));
     regSub(store, ['query', 'term'], query);
+    regSub(store, ['query', 'term'], complete);
 
     getLocationHash()
     search.focus();
@@ -269,7 +420,8 @@ function main() {
     const files = document.getElementById('files');
     const search = document.getElementById('search');
     const searchSpinner = document.getElementById('searchSpinner')
-    Exec(breadcrumbs, code, files, search, searchSpinner)
+    const completions = document.getElementById('completions');
+    Exec(breadcrumbs, code, files, search, searchSpinner, completions)
 }
 
 window.onload = main;
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"text/scanner"
+	"unicode"
+
+	"github.com/nfisher/mdindexer/stem"
+)
+
+// Tokenizer emits the words in a document and the field each was found in. It returns an
+// error if the document could not be read in full.
+type Tokenizer func(r io.Reader, emit emitFunc) error
+
+// TokenFilter transforms a word, returning an empty string removes it.
+type TokenFilter func(word string) string
+
+// Analyzer turns text into the words which are indexed. The same filters are applied to
+// query terms so they match the indexed form.
+type Analyzer struct {
+	Tokenizer Tokenizer
+	Filters   []TokenFilter
+	// Symbols optionally lists the declarations in a document.
+	Symbols func(src []byte) []Symbol
+}
+
+// Analyze counts the filtered words in a document by field and records their positions.
+func (a *Analyzer) Analyze(r io.Reader) (*Document, error) {
+	if a.Symbols == nil {
+		return a.words(r)
+	}
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := a.words(bytes.NewReader(src))
+	if err != nil {
+		return nil, err
+	}
+	doc.Symbols = a.Symbols(src)
+	return doc, nil
+}
+
+func (a *Analyzer) words(r io.Reader) (*Document, error) {
+	wordCount := make(map[string]int)
+	fields := make(map[string]FieldCounts)
+	positions := make(map[string][]int)
+	var pos int
+	err := a.Tokenizer(r, func(word string, field Field) {
+		// removed words still occupy a position so phrases spanning them can be matched,
+		// subwords share the position of the identifier they were split from
+		if field != FieldSubword {
+			pos++
+		}
+		word = a.Term(word)
+		if word == "" {
+			return
+		}
+		wordCount[word]++
+		counts := fields[word]
+		counts[field]++
+		fields[word] = counts
+		if p := positions[word]; len(p) == 0 || p[len(p)-1] != pos-1 {
+			positions[word] = append(p, pos-1)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Document{WordCount: wordCount, Fields: fields, Positions: positions}, nil
+}
+
+// Term applies the filters to a single word returning an empty string if it is removed.
+func (a *Analyzer) Term(word string) string {
+	for _, filter := range a.Filters {
+		word = filter(word)
+		if word == "" {
+			break
+		}
+	}
+	return word
+}
+
+// LowercaseFilter folds words to lower case.
+func LowercaseFilter(word string) string {
+	return strings.ToLower(word)
+}
+
+// StopFilter removes stopWords.
+func StopFilter(stopWords StopWords) TokenFilter {
+	return func(word string) string {
+		if stopWords[word] {
+			return ""
+		}
+		return word
+	}
+}
+
+// StemFilter reduces English words to their Porter stem.
+func StemFilter(word string) string {
+	return stem.Porter(word)
+}
+
+// NewStopWords returns the set of words.
+func NewStopWords(words []string) StopWords {
+	stopWords := make(StopWords, len(words))
+	for _, w := range words {
+		stopWords[w] = true
+	}
+	return stopWords
+}
+
+// tokenizeIdents emits the identifiers in source code.
+func tokenizeIdents(r io.Reader, emit emitFunc) error {
+	var s scanner.Scanner
+	s.Init(r)
+	s.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats
+	for tok := s.Scan(); tok != scanner.EOF; tok = s.Scan() {
+		if tok == scanner.Ident {
+			emit(s.TokenText(), FieldBody)
+		}
+	}
+	return nil
+}
+
+// splitIdent breaks an identifier at underscores, lower to upper case transitions and
+// between letters and digits. An acronym is kept whole, HTTPServer is HTTP and Server.
+func splitIdent(ident string) []string {
+	runes := []rune(ident)
+	var parts []string
+	start := 0
+	for i := 0; i <= len(runes); i++ {
+		if i < len(runes) && runes[i] != '_' && !identBoundary(runes, i) {
+			continue
+		}
+		if i > start {
+			parts = append(parts, string(runes[start:i]))
+		}
+		start = i
+		if i < len(runes) && runes[i] == '_' {
+			start++
+		}
+	}
+	return parts
+}
+
+// identBoundary returns true if a new part of an identifier starts at runes[i].
+func identBoundary(runes []rune, i int) bool {
+	if i == 0 {
+		return false
+	}
+	prev, cur := runes[i-1], runes[i]
+	switch {
+	case unicode.IsDigit(prev) != unicode.IsDigit(cur):
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(cur):
+		return true
+	case unicode.IsUpper(prev) && unicode.IsUpper(cur):
+		return i+1 < len(runes) && unicode.IsLower(runes[i+1])
+	}
+	return false
+}
+
+// codeAnalyzer indexes the identifiers, comments and strings lex finds in source code.
+// The keywords are removed from query terms and comments.
+func codeAnalyzer(keywords []string, lex lexFunc) *Analyzer {
+	return &Analyzer{
+		Tokenizer: sourceTokenizer(lex),
+		Filters:   []TokenFilter{LowercaseFilter, StopFilter(NewStopWords(keywords))},
+	}
+}
+
+// englishAnalyzer indexes markdown prose excluding stopWords, optionally reducing words to
+// their stems.
+func englishAnalyzer(stopWords []string, stem bool) *Analyzer {
+	a := &Analyzer{
+		Tokenizer: tokenizeMarkdown,
+		Filters:   []TokenFilter{StopFilter(NewStopWords(stopWords))},
+	}
+	if stem {
+		a.Filters = append(a.Filters, StemFilter)
+	}
+	return a
+}
+
+// goAnalyzer indexes Go source and records its declarations.
+func goAnalyzer(keywords []string) *Analyzer {
+	a := codeAnalyzer(keywords, lexGo)
+	a.Symbols = goSymbols
+	return a
+}
+
+// newAnalyzer returns the analyzer of a built in language with its stop words replaced.
+// Stemming only applies to English.
+func newAnalyzer(language string, stopWords []string, stem bool) (*Analyzer, error) {
+	switch canonicalLanguage(language) {
+	case "english":
+		return englishAnalyzer(stopWords, stem), nil
+	case "go":
+		return goAnalyzer(stopWords), nil
+	case "java":
+		return codeAnalyzer(stopWords, lexJava(stopWords)), nil
+	case "js":
+		return codeAnalyzer(stopWords, lexJS(stopWords)), nil
+	}
+	return nil, fmt.Errorf("unknown analyzer %q", language)
+}
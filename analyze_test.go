@@ -0,0 +1,146 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_search_matches_stemmed_words(t *testing.T) {
+	index := New(3)
+	analyzer := analyzers["english"]
+	docs := map[string]string{
+		"ci.md":      "Building the project",
+		"release.md": "The release builds nightly",
+	}
+	for name, text := range docs {
+		doc, err := analyzer.Analyze(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc.Name = name
+		index.Update(doc)
+	}
+	index.SetAnalyzers(analyzer)
+
+	cases := map[string][]string{
+		"build":           {"ci.md", "release.md"},
+		"builds":          {"ci.md", "release.md"},
+		"building":        {"ci.md", "release.md"},
+		"the building":    {"ci.md", "release.md"},
+		"building -the":   {"ci.md", "release.md"},
+		"nightly release": {"release.md"},
+	}
+	for query, expected := range cases {
+		result, err := SearchWith(query, index, SearchOptions{Exact: true})
+		if err != nil {
+			t.Errorf("SearchWith(%q) error=%v, want nil", query, err)
+			continue
+		}
+		actual := documents(result.Docs)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("SearchWith(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, actual))
+		}
+		if len(result.Expansions) > 0 {
+			t.Errorf("SearchWith(%q) expansions=%v, want none", query, result.Expansions)
+		}
+	}
+}
+
+func Test_analyzer_term(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		analyzer *Analyzer
+		word     string
+		expected string
+	}{
+		"stemmed":      {analyzers["english"], "running", "run"},
+		"stop word":    {analyzers["english"], "the", ""},
+		"code keyword": {analyzers["go"], "Func", ""},
+		"code folded":  {analyzers["go"], "ReadFile", "readfile"},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			actual := tc.analyzer.Term(tc.word)
+			if actual != tc.expected {
+				t.Errorf("Term(%q)=%q, want %q", tc.word, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_split_ident(t *testing.T) {
+	t.Parallel()
+	cases := map[string][]string{
+		"BuildRoutes":      {"Build", "Routes"},
+		"buildRoutes":      {"build", "Routes"},
+		"build_routes":     {"build", "routes"},
+		"MAX_SIZE":         {"MAX", "SIZE"},
+		"HTTPServer":       {"HTTP", "Server"},
+		"parseHTTPRequest": {"parse", "HTTP", "Request"},
+		"sha256Sum":        {"sha", "256", "Sum"},
+		"_private":         {"private"},
+		"index":            {"index"},
+	}
+	for ident, expected := range cases {
+		actual := splitIdent(ident)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("splitIdent(%q) mismatch (-want +got)\n%s", ident, cmp.Diff(expected, actual))
+		}
+	}
+}
+
+func Test_search_matches_identifier_parts(t *testing.T) {
+	index := New(3)
+	analyzer := analyzers["java"]
+	docs := map[string]string{
+		"Server.java": "class Server { void BuildRoutes() {} }",
+		"Routes.java": "class Routes { Routes routes; }",
+		"Main.java":   "class Main { int max_size; }",
+	}
+	for name, text := range docs {
+		doc, err := analyzer.Analyze(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc.Name = name
+		index.Update(doc)
+	}
+	index.SetAnalyzers(analyzer)
+
+	cases := map[string][]string{
+		"routes":      {"Routes.java", "Server.java"},
+		"BuildRoutes": {"Server.java"},
+		"build":       {"Server.java"},
+		"size":        {"Main.java"},
+		"max_size":    {"Main.java"},
+	}
+	for query, expected := range cases {
+		result, err := SearchWith(query, index, SearchOptions{Exact: true})
+		if err != nil {
+			t.Errorf("SearchWith(%q) error=%v, want nil", query, err)
+			continue
+		}
+		var actual []string
+		for _, s := range result.Docs {
+			actual = append(actual, s.Document)
+		}
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("SearchWith(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, actual))
+		}
+	}
+}
+
+func Test_identifier_parts_share_its_position(t *testing.T) {
+	t.Parallel()
+	doc, err := analyzers["go"].Analyze(strings.NewReader("x := BuildRoutes(mux)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string][]int{"x": {0}, "buildroutes": {1}, "build": {1}, "routes": {1}, "mux": {2}}
+	if !cmp.Equal(doc.Positions, expected) {
+		t.Errorf("Positions mismatch (-want +got)\n%s", cmp.Diff(expected, doc.Positions))
+	}
+}
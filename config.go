@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// Config holds the indexer and server settings, read from the file named by -config.
+type Config struct {
+	// Addr is the host:port the server listens on, or unix: followed by the path of a
+	// Unix domain socket.
+	Addr string `json:"addr"`
+	// TLS serves HTTPS when it names a certificate.
+	TLS TLSConfig `json:"tls"`
+	// IndexFile is where the index is saved and loaded from on start.
+	IndexFile string `json:"index_file"`
+	// Watch keeps the index current as files change.
+	Watch bool `json:"watch"`
+	// Corpora are the directory trees to index.
+	Corpora []CorpusConfig `json:"corpora"`
+	// Languages adds languages or changes how the built in languages are read.
+	Languages map[string]LanguageConfig `json:"languages"`
+	Limits    Limits                    `json:"limits"`
+}
+
+// TLSConfig names the PEM encoded certificate and key, they are read again when changed.
+type TLSConfig struct {
+	CertFile string `json:"cert_file"`
+	KeyFile  string `json:"key_file"`
+}
+
+// CorpusConfig describes a directory tree to index.
+type CorpusConfig struct {
+	Path string `json:"path"`
+	// Languages of the files to index, all of them when empty.
+	Languages []string `json:"languages"`
+	// Include limits the files to those matching a glob relative to the path, such as
+	// docs/**/*.md. A glob starting with ! deselects files, as in !**/generated/**.
+	Include []string `json:"include"`
+	// Exclude skips the files and directories matching a glob.
+	Exclude []string `json:"exclude"`
+	// SkipDirs names directories which are never descended into, target by default. .git is
+	// always skipped.
+	SkipDirs []string `json:"skip_dirs"`
+	// IgnoreFiles names the files read with .gitignore semantics, .gitignore and
+	// .mdindexerignore by default. An empty list indexes ignored files too.
+	IgnoreFiles []string `json:"ignore_files"`
+	// FollowSymlinks descends into symbolic links to directories, links leading back to a
+	// directory above them are skipped.
+	FollowSymlinks bool `json:"follow_symlinks"`
+}
+
+// LanguageConfig describes how the files of a language are found and analyzed.
+type LanguageConfig struct {
+	// Extensions of the files in the language, such as .md.
+	Extensions []string `json:"extensions"`
+	// Analyzer is the built in language the files are read as, by default the language
+	// itself: english, go, java or js.
+	Analyzer string `json:"analyzer"`
+	// StopWords replaces the words which are never indexed.
+	StopWords []string `json:"stop_words"`
+	// Stem reduces English words to their stems, the default.
+	Stem *bool `json:"stem"`
+}
+
+// Limits bounds the resources used.
+type Limits struct {
+	// Workers is the number of files read concurrently, twice the CPUs when zero.
+	Workers int `json:"workers"`
+	// MaxFileSize is the size in bytes of the largest file indexed, any size when zero.
+	MaxFileSize int64 `json:"max_file_size"`
+}
+
+// defaultMaxFileSize skips files larger than people write by hand such as bundles and data.
+const defaultMaxFileSize = 1 << 20
+
+// DefaultConfig indexes every language below the working directory.
+func DefaultConfig() *Config {
+	return &Config{
+		Addr:    "127.0.0.1:8000",
+		Corpora: []CorpusConfig{{Path: "."}},
+		Limits:  Limits{MaxFileSize: defaultMaxFileSize},
+	}
+}
+
+// LoadConfig reads a JSON config file over the defaults. Unknown settings are rejected so
+// a misspelt setting is not silently ignored.
+func LoadConfig(filename string) (*Config, error) {
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	cfg := DefaultConfig()
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	err = dec.Decode(cfg)
+	if err != nil {
+		var serr *json.SyntaxError
+		if errors.As(err, &serr) {
+			return nil, fmt.Errorf("%s:%d: %v", filename, bytes.Count(b[:serr.Offset], []byte("\n"))+1, err)
+		}
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return cfg, nil
+}
+
+// Validate checks every setting returning the first problem found.
+func (c *Config) Validate() error {
+	if strings.HasPrefix(c.Addr, unixPrefix) {
+		if c.Addr == unixPrefix {
+			return fmt.Errorf("addr: unix: must be followed by the path of a socket")
+		}
+	} else if _, _, err := net.SplitHostPort(c.Addr); err != nil {
+		return fmt.Errorf("addr: %v", err)
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		return fmt.Errorf("tls: cert_file and key_file must be given together")
+	}
+	for _, name := range []string{c.TLS.CertFile, c.TLS.KeyFile} {
+		if name == "" {
+			continue
+		}
+		if _, err := os.Stat(name); err != nil {
+			return fmt.Errorf("tls: %v", err)
+		}
+	}
+	if c.Limits.Workers < 0 {
+		return fmt.Errorf("limits.workers: must not be negative")
+	}
+	if c.Limits.MaxFileSize < 0 {
+		return fmt.Errorf("limits.max_file_size: must not be negative")
+	}
+	for name, lang := range c.Languages {
+		// names are matched lower case, as written in -lang and lang: filters
+		if name != strings.ToLower(name) {
+			return fmt.Errorf("languages.%s: name must be lower case", name)
+		}
+		if _, err := lang.analyzer(name); err != nil {
+			return fmt.Errorf("languages.%s: %v", name, err)
+		}
+		if !builtinLanguages.Known(name) && len(lang.Extensions) == 0 {
+			return fmt.Errorf("languages.%s: extensions are required for a new language", name)
+		}
+		for _, ext := range lang.Extensions {
+			if !strings.HasPrefix(ext, ".") || len(ext) < 2 {
+				return fmt.Errorf("languages.%s: extension %q must start with a dot", name, ext)
+			}
+		}
+	}
+	if len(c.Corpora) == 0 {
+		return fmt.Errorf("corpora: at least one corpus is required")
+	}
+	for i, corpus := range c.Corpora {
+		if err := c.validateCorpus(corpus); err != nil {
+			return fmt.Errorf("corpora[%d]: %v", i, err)
+		}
+	}
+	return nil
+}
+
+// overrideCorpora replaces the paths of the corpora when paths is not empty, each is
+// indexed with the other settings of the first corpus. The languages of every corpus are
+// replaced when languages is not empty.
+func (c *Config) overrideCorpora(paths []string, languages []string) {
+	if len(paths) > 0 {
+		var template CorpusConfig
+		if len(c.Corpora) > 0 {
+			template = c.Corpora[0]
+		}
+		c.Corpora = make([]CorpusConfig, 0, len(paths))
+		for _, p := range paths {
+			corpus := template
+			corpus.Path = p
+			c.Corpora = append(c.Corpora, corpus)
+		}
+	}
+	if len(languages) > 0 {
+		for i := range c.Corpora {
+			c.Corpora[i].Languages = languages
+		}
+	}
+}
+
+func (c *Config) validateCorpus(corpus CorpusConfig) error {
+	info, err := os.Stat(corpus.Path)
+	if err != nil {
+		return fmt.Errorf("path: %v", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("path: %s is not a directory", corpus.Path)
+	}
+	for _, lang := range corpus.Languages {
+		lang = strings.ToLower(lang)
+		_, custom := c.Languages[lang]
+		if lang != "auto" && !builtinLanguages.Known(lang) && !custom {
+			return fmt.Errorf("languages: unknown language %q", lang)
+		}
+	}
+	return (&Corpus{Include: corpus.Include, Exclude: corpus.Exclude}).Validate()
+}
+
+func (l LanguageConfig) analyzer(name string) (*Analyzer, error) {
+	base := l.Analyzer
+	if base == "" {
+		base = name
+	}
+	stopWords := l.StopWords
+	if stopWords == nil {
+		stopWords = defaultStopWords[canonicalLanguage(base)]
+	}
+	stem := l.Stem == nil || *l.Stem
+	return newAnalyzer(base, stopWords, stem)
+}
+
+// analysis hashes the language settings so an index read with other extensions, analyzers,
+// stop words or stemming can be detected.
+func (c *Config) analysis() (uint64, error) {
+	// maps are encoded with sorted keys so equal settings hash equally
+	b, err := json.Marshal(c.Languages)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	h.Write(b)
+	return h.Sum64(), nil
+}
+
+// Indexer returns an indexer for the corpora with the built in languages and those the
+// config defines. The config must be valid.
+func (c *Config) Indexer() (*Indexer, error) {
+	registry := NewRegistry()
+	names := make([]string, 0, len(c.Languages))
+	for name := range c.Languages {
+		names = append(names, name)
+	}
+	// registered in order so -lang auto lists them the same way each run
+	sort.Strings(names)
+	for _, name := range names {
+		lang := c.Languages[name]
+		analyzer, err := lang.analyzer(name)
+		if err != nil {
+			return nil, err
+		}
+		registry.Register(name, lang.Extensions, analyzer)
+	}
+
+	analysis, err := c.analysis()
+	if err != nil {
+		return nil, err
+	}
+	ix := &Indexer{Workers: c.Limits.Workers, Analysis: analysis, Registry: registry}
+	if ix.Workers == 0 {
+		ix.Workers = runtime.NumCPU() * 2
+	}
+	for _, cc := range c.Corpora {
+		spec := strings.Join(cc.Languages, ",")
+		if spec == "" {
+			spec = "auto"
+		}
+		langs, err := registry.Parse(spec)
+		if err != nil {
+			return nil, err
+		}
+		corpus := registry.NewCorpus(cc.Path, langs)
+		corpus.Include = cc.Include
+		corpus.Exclude = cc.Exclude
+		corpus.MaxSize = c.Limits.MaxFileSize
+		corpus.FollowSymlinks = cc.FollowSymlinks
+		if cc.SkipDirs != nil {
+			corpus.SkipDirs = cc.SkipDirs
+		}
+		if cc.IgnoreFiles != nil {
+			corpus.IgnoreFiles = cc.IgnoreFiles
+		}
+		ix.Corpora = append(ix.Corpora, corpus)
+		for _, lang := range langs {
+			ix.Languages = ix.Languages.add(lang)
+		}
+	}
+	return ix, nil
+}
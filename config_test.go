@@ -0,0 +1,216 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeConfig(t *testing.T, text string) string {
+	t.Helper()
+	f, err := ioutil.TempFile("", "mdindexer*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	_, err = f.WriteString(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f.Name()
+}
+
+func Test_load_config(t *testing.T) {
+	t.Parallel()
+	filename := writeConfig(t, `{
+		"addr": ":9000",
+		"corpora": [{"path": "docs", "languages": ["markdown"], "exclude": ["drafts/*"]}],
+		"languages": {"notes": {"extensions": [".txt"], "analyzer": "english", "stem": false}},
+		"limits": {"workers": 3}
+	}`)
+	defer os.Remove(filename)
+
+	cfg, err := LoadConfig(filename)
+	if err != nil {
+		t.Fatalf("LoadConfig() error=%v, want nil", err)
+	}
+	stem := false
+	expected := &Config{
+		Addr:      ":9000",
+		Corpora:   []CorpusConfig{{Path: "docs", Languages: []string{"markdown"}, Exclude: []string{"drafts/*"}}},
+		Languages: map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english", Stem: &stem}},
+		Limits:    Limits{Workers: 3, MaxFileSize: defaultMaxFileSize},
+	}
+	if !cmp.Equal(cfg, expected) {
+		t.Errorf("LoadConfig() mismatch (-want +got)\n%s", cmp.Diff(expected, cfg))
+	}
+}
+
+func Test_load_config_errors(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		text     string
+		expected string
+	}{
+		"unknown setting": {`{"adr": ":9000"}`, `unknown field "adr"`},
+		"syntax":          {"{\n\"addr\": \":9000\",\n}", ".json:3: "},
+		"type":            {`{"watch": "yes"}`, "cannot unmarshal string"},
+	}
+	for name, tc := range cases {
+		filename := writeConfig(t, tc.text)
+		defer os.Remove(filename)
+		_, err := LoadConfig(filename)
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: LoadConfig() error=%v, want containing %q", name, err, tc.expected)
+		}
+	}
+}
+
+func Test_validate_config(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "README.md")
+	err = ioutil.WriteFile(file, []byte("# Hello"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		change   func(cfg *Config)
+		expected string
+	}{
+		"defaults": {func(cfg *Config) {}, ""},
+		"custom language": {func(cfg *Config) {
+			cfg.Languages = map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english"}}
+			cfg.Corpora[0].Languages = []string{"notes"}
+		}, ""},
+		"addr":         {func(cfg *Config) { cfg.Addr = "8000" }, "addr: "},
+		"unix addr":    {func(cfg *Config) { cfg.Addr = "unix:" + filepath.Join(dir, "mdindexer.sock") }, ""},
+		"unix path":    {func(cfg *Config) { cfg.Addr = "unix:" }, "addr: unix: must be followed by the path of a socket"},
+		"tls pair":     {func(cfg *Config) { cfg.TLS.CertFile = file }, "tls: cert_file and key_file must be given together"},
+		"tls missing":  {func(cfg *Config) { cfg.TLS = TLSConfig{CertFile: file, KeyFile: filepath.Join(dir, "key.pem")} }, "tls: "},
+		"workers":      {func(cfg *Config) { cfg.Limits.Workers = -1 }, "limits.workers: must not be negative"},
+		"no corpora":   {func(cfg *Config) { cfg.Corpora = nil }, "corpora: at least one corpus is required"},
+		"missing path": {func(cfg *Config) { cfg.Corpora[0].Path = filepath.Join(dir, "missing") }, "corpora[0]: path: "},
+		"file path":    {func(cfg *Config) { cfg.Corpora[0].Path = file }, "is not a directory"},
+		"lang case": {func(cfg *Config) {
+			cfg.Languages = map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english"}}
+			cfg.Corpora[0].Languages = []string{"Notes"}
+		}, ""},
+		"name case": {func(cfg *Config) {
+			cfg.Languages = map[string]LanguageConfig{"Notes": {Extensions: []string{".txt"}, Analyzer: "english"}}
+		}, "languages.Notes: name must be lower case"},
+		"unknown lang":  {func(cfg *Config) { cfg.Corpora[0].Languages = []string{"cobol"} }, `corpora[0]: languages: unknown language "cobol"`},
+		"glob":          {func(cfg *Config) { cfg.Corpora[0].Exclude = []string{"[a"} }, `corpora[0]: invalid glob "[a"`},
+		"analyzer":      {func(cfg *Config) { cfg.Languages = map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}}} }, "languages.notes: "},
+		"extensions":    {func(cfg *Config) { cfg.Languages = map[string]LanguageConfig{"notes": {Analyzer: "english"}} }, "languages.notes: extensions are required"},
+		"extension dot": {func(cfg *Config) { cfg.Languages = map[string]LanguageConfig{"go": {Extensions: []string{"go"}}} }, `languages.go: extension "go" must start with a dot`},
+	}
+	for name, tc := range cases {
+		cfg := DefaultConfig()
+		cfg.Corpora[0].Path = dir
+		tc.change(cfg)
+		err := cfg.Validate()
+		if tc.expected == "" {
+			if err != nil {
+				t.Errorf("%s: Validate() error=%v, want nil", name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.expected) {
+			t.Errorf("%s: Validate() error=%v, want containing %q", name, err, tc.expected)
+		}
+	}
+}
+
+func Test_config_analysis_follows_language_settings(t *testing.T) {
+	t.Parallel()
+	stem := false
+	base := map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english"}}
+	cases := map[string]struct {
+		languages map[string]LanguageConfig
+		same      bool
+	}{
+		"unchanged":  {map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english"}}, true},
+		"analyzer":   {map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "german"}}, false},
+		"stop words": {map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english", StopWords: []string{"the"}}}, false},
+		"stem":       {map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english", Stem: &stem}}, false},
+		"none":       {nil, false},
+	}
+	expected, err := (&Config{Languages: base}).analysis()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, tc := range cases {
+		actual, err := (&Config{Languages: tc.languages}).analysis()
+		if err != nil {
+			t.Errorf("%s: analysis() error=%v, want nil", name, err)
+			continue
+		}
+		if (actual == expected) != tc.same {
+			t.Errorf("%s: analysis()=%d, base %d, want same=%v", name, actual, expected, tc.same)
+		}
+	}
+}
+
+func Test_config_indexer_keeps_its_languages(t *testing.T) {
+	t.Parallel()
+	cfg := DefaultConfig()
+	cfg.Languages = map[string]LanguageConfig{"notes": {Extensions: []string{".txt"}, Analyzer: "english"}}
+	cfg.Corpora[0].Languages = []string{"notes"}
+	ix, err := cfg.Indexer()
+	if err != nil {
+		t.Fatalf("Indexer() error=%v, want nil", err)
+	}
+	if lang := ix.Registry.Of(ix.Languages, "todo.txt"); lang != "notes" {
+		t.Errorf("Registry.Of(todo.txt)=%q, want notes", lang)
+	}
+
+	other, err := DefaultConfig().Indexer()
+	if err != nil {
+		t.Fatalf("Indexer() error=%v, want nil", err)
+	}
+	if builtinLanguages.Known("notes") || other.Registry.Known("notes") {
+		t.Errorf("notes is known outside the config which defined it")
+	}
+	if _, err = parseQuery("lang:notes", ix.Registry); err != nil {
+		t.Errorf("parseQuery(lang:notes) error=%v, want nil", err)
+	}
+	if _, err = ParseQuery("lang:notes"); err == nil {
+		t.Errorf("ParseQuery(lang:notes) error=nil, want unknown language")
+	}
+}
+
+func Test_override_corpora_keeps_settings(t *testing.T) {
+	t.Parallel()
+	configured := []CorpusConfig{{Path: "docs", Languages: []string{"markdown"}, SkipDirs: []string{"build"}, IgnoreFiles: []string{}}}
+	cases := map[string]struct {
+		paths     []string
+		languages []string
+		expected  []CorpusConfig
+	}{
+		"none": {nil, nil, configured},
+		"start": {[]string{"a", "b"}, nil, []CorpusConfig{
+			{Path: "a", Languages: []string{"markdown"}, SkipDirs: []string{"build"}, IgnoreFiles: []string{}},
+			{Path: "b", Languages: []string{"markdown"}, SkipDirs: []string{"build"}, IgnoreFiles: []string{}},
+		}},
+		"lang": {nil, []string{"go"}, []CorpusConfig{
+			{Path: "docs", Languages: []string{"go"}, SkipDirs: []string{"build"}, IgnoreFiles: []string{}},
+		}},
+	}
+	for name, tc := range cases {
+		cfg := &Config{Corpora: append([]CorpusConfig(nil), configured...)}
+		cfg.overrideCorpora(tc.paths, tc.languages)
+		if !cmp.Equal(cfg.Corpora, tc.expected) {
+			t.Errorf("%s: overrideCorpora() mismatch (-want +got)\n%s", name, cmp.Diff(tc.expected, cfg.Corpora))
+		}
+	}
+}
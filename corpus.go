@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// defaultSkipDirs are the directories never descended into unless a corpus names its own.
+var defaultSkipDirs = []string{"target"}
+
+// gitDir is always skipped whatever directories a corpus names.
+const gitDir = ".git"
+
+// Corpus is a directory tree of documents to index.
+type Corpus struct {
+	Path string
+	// Pattern matches the base names of the files to index.
+	Pattern *regexp.Regexp
+	// Include limits the files indexed to those matching a glob, such as docs/**/*.md. A glob
+	// starting with ! deselects the files an earlier glob selected.
+	Include []string
+	// Exclude skips the files and directories matching a glob. A glob starting with !
+	// keeps the files an earlier glob skipped.
+	Exclude []string
+	// SkipDirs names the directories which are never descended into as well as .git.
+	SkipDirs []string
+	// IgnoreFiles names the files read with .gitignore semantics in each directory.
+	IgnoreFiles []string
+	// MaxSize is the size in bytes of the largest file indexed, any size when zero.
+	MaxSize int64
+	// FollowSymlinks descends into symbolic links to directories.
+	FollowSymlinks bool
+
+	ignores ignores
+}
+
+// NewCorpus returns a corpus of the files below path in the given built in languages.
+func NewCorpus(path string, langs Languages) *Corpus {
+	return builtinLanguages.NewCorpus(path, langs)
+}
+
+// NewCorpus returns a corpus of the files below path in the given languages.
+func (r *Registry) NewCorpus(path string, langs Languages) *Corpus {
+	return &Corpus{
+		Path:        path,
+		Pattern:     regexp.MustCompile(r.Pattern(langs)),
+		SkipDirs:    defaultSkipDirs,
+		IgnoreFiles: defaultIgnoreFiles,
+	}
+}
+
+// Validate checks the globs are well formed.
+func (c *Corpus) Validate() error {
+	for _, glob := range append(append([]string(nil), c.Include...), c.Exclude...) {
+		if _, err := path.Match(strings.TrimPrefix(glob, "!"), ""); err != nil {
+			return fmt.Errorf("invalid glob %q: %v", glob, err)
+		}
+	}
+	if c.MaxSize < 0 {
+		return fmt.Errorf("max file size must not be negative")
+	}
+	return nil
+}
+
+// rel returns name relative to the corpus root with forward slashes, or false if name is
+// not below it.
+func (c *Corpus) rel(name string) (string, bool) {
+	rel, err := filepath.Rel(filepath.Clean(c.Path), name)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// SkipDir returns true if the directory name should not be descended into.
+func (c *Corpus) SkipDir(name string) bool {
+	base := filepath.Base(name)
+	if base == gitDir {
+		return true
+	}
+	for _, dir := range c.SkipDirs {
+		if base == dir {
+			return true
+		}
+	}
+	rel, ok := c.rel(name)
+	return ok && rel != "." && (matchGlobs(c.Exclude, rel, false) || c.ignored(rel, true))
+}
+
+// Match returns true if the file name should be indexed.
+func (c *Corpus) Match(name string) bool {
+	rel, ok := c.rel(name)
+	if !ok || !c.Pattern.MatchString(filepath.Base(name)) {
+		return false
+	}
+	if !matchGlobs(c.Include, rel, !hasSelecting(c.Include)) {
+		return false
+	}
+	return !matchGlobs(c.Exclude, rel, false) && !c.ignored(rel, false)
+}
+
+// MatchFile returns true if the file name should be indexed and is small enough.
+func (c *Corpus) MatchFile(name string, info os.FileInfo) bool {
+	return (c.MaxSize == 0 || info.Size() <= c.MaxSize) && c.Match(name)
+}
+
+// matchGlobs returns whether the last glob matching rel selects it, or def when none do.
+// A glob starting with ! deselects and a glob without a slash is matched against the base
+// name, as in *.min.js.
+func matchGlobs(globs []string, rel string, def bool) bool {
+	selected := def
+	for _, glob := range globs {
+		negate := strings.HasPrefix(glob, "!")
+		glob = strings.TrimPrefix(glob, "!")
+		name := rel
+		if !strings.Contains(glob, "/") {
+			name = path.Base(rel)
+		}
+		if matchGlob(glob, name) {
+			selected = !negate
+		}
+	}
+	return selected
+}
+
+// hasSelecting returns true if a glob does not start with !. Include lists made of
+// negations alone select everything else.
+func hasSelecting(globs []string) bool {
+	for _, glob := range globs {
+		if !strings.HasPrefix(glob, "!") {
+			return true
+		}
+	}
+	return false
+}
+
+// Corpora are the corpus indexed by a single index.
+type Corpora []*Corpus
+
+// Paths returns the root of each corpus.
+func (cs Corpora) Paths() []string {
+	paths := make([]string, 0, len(cs))
+	for _, c := range cs {
+		paths = append(paths, c.Path)
+	}
+	return paths
+}
+
+// SkipDir returns true if every corpus containing the directory name skips it.
+func (cs Corpora) SkipDir(name string) bool {
+	for _, c := range cs {
+		if _, ok := c.rel(name); ok && !c.SkipDir(name) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match returns true if any corpus indexes the file name.
+func (cs Corpora) Match(name string) bool {
+	for _, c := range cs {
+		if c.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFile returns true if any corpus indexes the file name of the given size.
+func (cs Corpora) MatchFile(name string, info os.FileInfo) bool {
+	for _, c := range cs {
+		if c.MatchFile(name, info) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsIgnoreFile returns true if name is an ignore file of any corpus.
+func (cs Corpora) IsIgnoreFile(name string) bool {
+	for _, c := range cs {
+		if c.isIgnoreFile(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// forgetIgnores discards the cached ignore rules of dir in every corpus.
+func (cs Corpora) forgetIgnores(dir string) {
+	for _, c := range cs {
+		c.forgetIgnores(dir)
+	}
+}
+
+// Documents returns the files to index in every corpus without duplicates and the paths
+// skipped.
+func (cs Corpora) Documents() ([]string, []SkippedPath) {
+	seen := make(map[string]bool)
+	var docs []string
+	var skipped []SkippedPath
+	for _, c := range cs {
+		list, s := c.Documents()
+		skipped = append(skipped, s...)
+		for _, doc := range list {
+			if !seen[doc] {
+				seen[doc] = true
+				docs = append(docs, doc)
+			}
+		}
+	}
+	sort.Strings(docs)
+	return docs, skipped
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_corpus_documents(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, name := range []string{
+		"README.md",
+		"main.go",
+		"docs/guide.md",
+		"docs/drafts/idea.md",
+		"target/out.md",
+		".git/HEAD.md",
+		"vendor/lib.md",
+		"web/app.min.js",
+		"docs/api/generated/types.md",
+	} {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		text := "hello"
+		if name == "README.md" {
+			text = "hello world"
+		}
+		err = ioutil.WriteFile(filename, []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := map[string]struct {
+		corpus   func(c *Corpus)
+		expected []string
+	}{
+		"defaults":         {func(c *Corpus) {}, []string{"README.md", "docs/api/generated/types.md", "docs/drafts/idea.md", "docs/guide.md", "main.go", "vendor/lib.md", "web/app.min.js"}},
+		"include":          {func(c *Corpus) { c.Include = []string{"docs/*.md", "*.go"} }, []string{"docs/guide.md", "main.go"}},
+		"include globstar": {func(c *Corpus) { c.Include = []string{"docs/**/*.md", "!**/generated/**"} }, []string{"docs/drafts/idea.md", "docs/guide.md"}},
+		"include negation": {func(c *Corpus) { c.Include = []string{"!**/generated/**", "!*.js"} }, []string{"README.md", "docs/drafts/idea.md", "docs/guide.md", "main.go", "vendor/lib.md"}},
+		"exclude":          {func(c *Corpus) { c.Exclude = []string{"docs/drafts", "*.min.js"} }, []string{"README.md", "docs/api/generated/types.md", "docs/guide.md", "main.go", "vendor/lib.md"}},
+		"exclude negation": {func(c *Corpus) { c.Exclude = []string{"docs/**", "!docs/guide.md"} }, []string{"README.md", "docs/guide.md", "main.go", "vendor/lib.md", "web/app.min.js"}},
+		"skip dirs":        {func(c *Corpus) { c.SkipDirs = []string{"vendor", "docs"} }, []string{"README.md", "main.go", "target/out.md", "web/app.min.js"}},
+		"max size":         {func(c *Corpus) { c.MaxSize = 5; c.Include = []string{"*.md"} }, []string{"docs/api/generated/types.md", "docs/drafts/idea.md", "docs/guide.md", "vendor/lib.md"}},
+	}
+	for name, tc := range cases {
+		c := NewCorpus(dir, Languages{"go", "js", "english"})
+		tc.corpus(c)
+		docs, _ := c.Documents()
+		var actual []string
+		for _, doc := range docs {
+			rel, _ := filepath.Rel(dir, doc)
+			actual = append(actual, filepath.ToSlash(rel))
+		}
+		if !cmp.Equal(actual, tc.expected) {
+			t.Errorf("%s: Documents() mismatch (-want +got)\n%s", name, cmp.Diff(tc.expected, actual))
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxLoggedSkips bounds the skipped paths logged after each discovery, the rest are
+// available from /discovery.
+const maxLoggedSkips = 20
+
+// SkippedPath is a file or directory which could not be indexed.
+type SkippedPath struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Discovery reports the outcome of the last walk of the corpora.
+type Discovery struct {
+	Time      time.Time     `json:"time"`
+	Corpora   []string      `json:"corpora"`
+	Documents int           `json:"documents"`
+	Skipped   []SkippedPath `json:"skipped"`
+}
+
+// discoveries holds the latest discovery of an indexer, files which fail to read are added
+// as they are indexed.
+type discoveries struct {
+	sync.Mutex
+	last Discovery
+}
+
+func (d *discoveries) set(report Discovery) {
+	d.Lock()
+	d.last = report
+	d.Unlock()
+}
+
+func (d *discoveries) skip(path string, err error) {
+	d.Lock()
+	d.last.Skipped = append(d.last.Skipped, SkippedPath{Path: path, Reason: err.Error()})
+	d.Unlock()
+}
+
+// Discovery returns a copy of the latest discovery report.
+func (ix *Indexer) Discovery() Discovery {
+	ix.discoveries.Lock()
+	defer ix.discoveries.Unlock()
+	report := ix.discoveries.last
+	report.Skipped = append([]SkippedPath{}, report.Skipped...)
+	return report
+}
+
+// logDiscovery summarises the latest discovery report.
+func (ix *Indexer) logDiscovery() {
+	report := ix.Discovery()
+	log.Printf("discovery=success start=`%v` languages=`%v` documents=%d skipped=%d\n",
+		report.Corpora, ix.Languages, report.Documents, len(report.Skipped))
+	for i, skip := range report.Skipped {
+		if i == maxLoggedSkips {
+			log.Printf("discovery=skipped more=%d\n", len(report.Skipped)-i)
+			break
+		}
+		log.Printf("discovery=skipped path=%s reason='%s'\n", skip.Path, skip.Reason)
+	}
+}
+
+// walk calls fn with every file below the corpus root which is not in a skipped or ignored
+// directory. Paths which cannot be read are reported and the walk continues past them.
+// Symbolic links to directories are followed when FollowSymlinks is set unless they lead
+// back to a directory being walked.
+func (c *Corpus) walk(fn func(name string, info os.FileInfo)) []SkippedPath {
+	return c.walkDir(c.Path, nil, fn)
+}
+
+// walkDir walks dir, the corpus root or a directory below it, in the same way as walk.
+// When enter is not nil it is called with each directory before it is read and a
+// directory it returns false for is not descended into.
+func (c *Corpus) walkDir(dir string, enter func(dir string) bool, fn func(name string, info os.FileInfo)) []SkippedPath {
+	var skipped []SkippedPath
+	ancestors, err := c.ancestors(dir)
+	if err != nil {
+		return append(skipped, SkippedPath{Path: dir, Reason: err.Error()})
+	}
+	if !ancestors[len(ancestors)-1].IsDir() {
+		return append(skipped, SkippedPath{Path: dir, Reason: "not a directory"})
+	}
+
+	var visit func(dir string, ancestors []os.FileInfo)
+	visit = func(dir string, ancestors []os.FileInfo) {
+		if enter != nil && !enter(dir) {
+			return
+		}
+		entries, err := ioutil.ReadDir(dir)
+		if err != nil {
+			skipped = append(skipped, SkippedPath{Path: dir, Reason: err.Error()})
+			return
+		}
+		for _, info := range entries {
+			name := filepath.Join(dir, info.Name())
+			if info.Mode()&os.ModeSymlink != 0 {
+				target, err := os.Stat(name)
+				if err != nil {
+					skipped = append(skipped, SkippedPath{Path: name, Reason: err.Error()})
+					continue
+				}
+				if target.IsDir() && !c.FollowSymlinks {
+					continue
+				}
+				if target.IsDir() && isAncestor(target, ancestors) {
+					skipped = append(skipped, SkippedPath{Path: name, Reason: "symbolic link cycle"})
+					continue
+				}
+				info = target
+			}
+			if !info.IsDir() {
+				fn(name, info)
+				continue
+			}
+			if !c.SkipDir(name) {
+				visit(name, append(ancestors[:len(ancestors):len(ancestors)], info))
+			}
+		}
+	}
+	visit(dir, ancestors)
+	return skipped
+}
+
+// ancestors returns the directories from the corpus root down to dir so links leading back
+// above dir are recognised as cycles.
+func (c *Corpus) ancestors(dir string) ([]os.FileInfo, error) {
+	names := []string{dir}
+	if rel, ok := c.rel(dir); ok && rel != "." {
+		names = []string{c.Path}
+		for _, part := range strings.Split(rel, "/") {
+			names = append(names, filepath.Join(names[len(names)-1], part))
+		}
+	}
+	infos := make([]os.FileInfo, 0, len(names))
+	for _, name := range names {
+		info, err := os.Stat(name)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+func isAncestor(dir os.FileInfo, ancestors []os.FileInfo) bool {
+	for _, a := range ancestors {
+		if os.SameFile(dir, a) {
+			return true
+		}
+	}
+	return false
+}
+
+// Documents walks the corpus returning the files to index and the paths skipped.
+func (c *Corpus) Documents() ([]string, []SkippedPath) {
+	var docs []string
+	var large []SkippedPath
+	skipped := c.walk(func(name string, info os.FileInfo) {
+		if c.MatchFile(name, info) {
+			docs = append(docs, name)
+		} else if c.Match(name) {
+			large = append(large, SkippedPath{Path: name, Reason: fmt.Sprintf("larger than %d bytes", c.MaxSize)})
+		}
+	})
+	return docs, append(skipped, large...)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_walk_follows_symlinks(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	shared, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(shared)
+
+	err = ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte("hello"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(shared, "shared.md"), []byte("hello"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"shared":  shared,
+		"loop":    ".",
+		"broken":  "missing.md",
+		"link.md": "README.md",
+	}
+	for name, target := range links {
+		err = os.Symlink(target, filepath.Join(dir, name))
+		if err != nil {
+			t.Skipf("symlinks unavailable: %v", err)
+		}
+	}
+
+	cases := map[string]struct {
+		follow  bool
+		docs    []string
+		skipped []SkippedPath
+	}{
+		"not followed": {false, []string{"README.md", "link.md"}, []SkippedPath{
+			{Path: "broken", Reason: "stat " + filepath.Join(dir, "broken") + ": no such file or directory"},
+		}},
+		"followed": {true, []string{"README.md", "link.md", "shared/shared.md"}, []SkippedPath{
+			{Path: "broken", Reason: "stat " + filepath.Join(dir, "broken") + ": no such file or directory"},
+			{Path: "loop", Reason: "symbolic link cycle"},
+		}},
+	}
+	for name, tc := range cases {
+		c := NewCorpus(dir, Languages{"english"})
+		c.FollowSymlinks = tc.follow
+		docs, skipped := c.Documents()
+		var actual []string
+		for _, doc := range docs {
+			rel, _ := filepath.Rel(dir, doc)
+			actual = append(actual, filepath.ToSlash(rel))
+		}
+		for i := range skipped {
+			skipped[i].Path, _ = filepath.Rel(dir, skipped[i].Path)
+		}
+		if !cmp.Equal(actual, tc.docs) {
+			t.Errorf("%s: Documents() mismatch (-want +got)\n%s", name, cmp.Diff(tc.docs, actual))
+		}
+		if !cmp.Equal(skipped, tc.skipped) {
+			t.Errorf("%s: Documents() skipped mismatch (-want +got)\n%s", name, cmp.Diff(tc.skipped, skipped))
+		}
+	}
+}
+
+func Test_build_reports_skipped_paths(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	binary := filepath.Join(dir, "image.md")
+	err = ioutil.WriteFile(binary, []byte("\x89PNG\x00"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	ix := &Indexer{
+		Corpora:   Corpora{NewCorpus(missing, Languages{"english"}), NewCorpus(dir, Languages{"english"})},
+		Languages: Languages{"english"},
+		Workers:   2,
+	}
+	index := ix.Build()
+	if index.Len() != 0 {
+		t.Errorf("Len()=%d, want 0", index.Len())
+	}
+	report := ix.Discovery()
+	expected := []SkippedPath{
+		{Path: missing, Reason: "stat " + missing + ": no such file or directory"},
+		{Path: binary, Reason: errNotText.Error()},
+	}
+	if report.Documents != 1 || !cmp.Equal(report.Skipped, expected) {
+		t.Errorf("Discovery() documents=%d skipped mismatch (-want +got)\n%s", report.Documents, cmp.Diff(expected, report.Skipped))
+	}
+
+	result, err := SearchWith("hello", index, DefaultSearchOptions)
+	if err != nil || len(result.Docs) != 0 {
+		t.Errorf("SearchWith() docs=%v error=%v, want none", result.Docs, err)
+	}
+}
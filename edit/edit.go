@@ -1,6 +1,45 @@
 package edit
 
-// Distance2 calculates the levenshtein distance using a 2 row matrix.
+// Costs weights each edit operation when calculating a distance.
+type Costs struct {
+	Insert     int
+	Delete     int
+	Substitute int
+	// Transpose is the cost of swapping adjacent runes, zero disables transpositions.
+	Transpose int
+}
+
+var (
+	// Levenshtein counts every insertion, deletion and substitution as one edit.
+	Levenshtein = Costs{Insert: 1, Delete: 1, Substitute: 1}
+	// Indel counts a substitution as a deletion and an insertion like Distance2.
+	Indel = Costs{Insert: 1, Delete: 1, Substitute: 2}
+	// Damerau also counts transposing adjacent runes, a common typo, as one edit.
+	Damerau = Costs{Insert: 1, Delete: 1, Substitute: 1, Transpose: 1}
+)
+
+// Distance calculates the cost of the edits which turn s1 into s2 comparing runes rather
+// than bytes. Transpositions follow the optimal string alignment rule where no substring
+// is edited more than once, so unlike Levenshtein and Indel, costs with transpositions
+// do not satisfy the triangle inequality.
+func (c Costs) Distance(s1, s2 string) int {
+	return NewMatcher(c).Distance(s1, s2)
+}
+
+// DistanceWithin calculates the distance between s1 and s2 returning false as soon as it
+// is known to exceed max.
+func (c Costs) DistanceWithin(s1, s2 string, max int) (int, bool) {
+	return NewMatcher(c).Within(s1, s2, max)
+}
+
+// DistanceWithin calculates the Indel distance between s1 and s2 comparing runes and
+// returning false as soon as it is known to exceed max.
+func DistanceWithin(s1, s2 string, max int) (int, bool) {
+	return Indel.DistanceWithin(s1, s2, max)
+}
+
+// Distance2 calculates the levenshtein distance using a 2 row matrix. It compares bytes,
+// use Costs.Distance for text which may contain multi-byte runes.
 func Distance2(s1, s2 string) int {
 	m := len(s1) + 1
 	n := len(s2) + 1
@@ -30,7 +69,8 @@ func Distance2(s1, s2 string) int {
 	return mat[(m-1)%2][n-1]
 }
 
-// Distance calculates the levenshtein distance using a full matrix.
+// Distance calculates the levenshtein distance using a full matrix. It compares bytes,
+// use Costs.Distance for text which may contain multi-byte runes.
 func Distance(s1, s2 string) int {
 	m := len(s1) + 1
 	n := len(s2) + 1
@@ -59,3 +59,53 @@ func Test_min_edit_distance_v2(t *testing.T) {
 		})
 	}
 }
+
+func BenchmarkCostsDistance(b *testing.B) {
+	var dist = 0
+	for i := 0; i < b.N; i++ {
+		dist = Indel.Distance("intention", "execution")
+	}
+	Dist = dist
+}
+
+func Test_indel_matches_distance2(t *testing.T) {
+	for n, tc := range testCases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			dist := Indel.Distance(tc.s1, tc.s2)
+			if dist != tc.dist {
+				t.Errorf("Indel.Distance(%s, %s)=%d, want %d", tc.s1, tc.s2, dist, tc.dist)
+			}
+		})
+	}
+}
+
+func Test_costs_distance(t *testing.T) {
+	cases := map[string]struct {
+		costs Costs
+		s1    string
+		s2    string
+		dist  int
+	}{
+		"umlaut indel":          {Indel, "über", "uber", 2},
+		"umlaut levenshtein":    {Levenshtein, "Straße", "Strasse", 2},
+		"japanese":              {Levenshtein, "東京都", "京都", 1},
+		"japanese substitution": {Indel, "日本語", "日本人", 2},
+		"empty":                 {Levenshtein, "", "größe", 5},
+		"transposition":         {Damerau, "teh", "the", 1},
+		"transposition runes":   {Damerau, "größe", "gröeß", 1},
+		"levenshtein swap":      {Levenshtein, "teh", "the", 2},
+		"weighted":              {Costs{Insert: 3, Delete: 1, Substitute: 5}, "cat", "cart", 3},
+		"weighted delete":       {Costs{Insert: 3, Delete: 1, Substitute: 5}, "cart", "cat", 1},
+		"osa":                   {Damerau, "ca", "abc", 3},
+	}
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			dist := tc.costs.Distance(tc.s1, tc.s2)
+			if dist != tc.dist {
+				t.Errorf("%+v.Distance(%s, %s)=%d, want %d", tc.costs, tc.s1, tc.s2, dist, tc.dist)
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package edit
+
+import "unicode/utf8"
+
+// Matcher calculates edit distances reusing its buffers between calls. A Matcher must not
+// be used concurrently.
+type Matcher struct {
+	costs Costs
+	r1    []rune
+	r2    []rune
+	prev2 []int
+	prev  []int
+	row   []int
+}
+
+// NewMatcher returns a Matcher which weights edits by costs.
+func NewMatcher(costs Costs) *Matcher {
+	return &Matcher{costs: costs}
+}
+
+// Distance calculates the cost of the edits which turn s1 into s2 comparing runes.
+func (m *Matcher) Distance(s1, s2 string) int {
+	d, _ := m.Within(s1, s2, m.costs.limit(utf8.RuneCountInString(s1), utf8.RuneCountInString(s2)))
+	return d
+}
+
+// limit returns a cost no distance between strings of n1 and n2 runes can exceed.
+func (c Costs) limit(n1, n2 int) int {
+	largest := c.Insert
+	for _, cost := range []int{c.Delete, c.Substitute, c.Transpose} {
+		if cost > largest {
+			largest = cost
+		}
+	}
+	return (n1 + n2) * largest
+}
+
+// Within calculates the distance between s1 and s2 returning false once it is known to
+// exceed max. Only the band of the matrix where the difference in position can be made
+// up within max is filled and the calculation stops as soon as a row exceeds max, so
+// distant strings are rejected cheaply. The distance returned with false is only known to
+// be greater than max.
+func (m *Matcher) Within(s1, s2 string, max int) (int, bool) {
+	c := m.costs
+	m.r1 = appendRunes(m.r1[:0], s1)
+	m.r2 = appendRunes(m.r2[:0], s2)
+	r1, r2 := m.r1, m.r2
+	// a larger max changes nothing and would overflow the band and the outside cost
+	if limit := c.limit(len(r1), len(r2)); max > limit {
+		max = limit
+	}
+
+	// each rune of length difference needs an insertion or deletion
+	indel := c.Insert
+	if c.Delete < indel {
+		indel = c.Delete
+	}
+	band := len(r1) + len(r2)
+	if indel > 0 && max/indel < band {
+		band = max / indel
+	}
+	if diff := len(r1) - len(r2); diff > band || -diff > band {
+		return max + 1, false
+	}
+
+	n := len(r2) + 1
+	m.prev2 = resize(m.prev2, n)
+	m.prev = resize(m.prev, n)
+	m.row = resize(m.row, n)
+	prev2, prev, row := m.prev2, m.prev, m.row
+	// cells outside the band hold a cost which can never be within max
+	outside := max + 1
+	for j := range prev {
+		prev[j] = j * c.Insert
+		if j > band {
+			prev[j] = outside
+		}
+	}
+	// a transposition skips a row so the previous row's minimum must also exceed max
+	prevBest := 0
+	for i := 1; i <= len(r1); i++ {
+		ch := r1[i-1]
+		lo, hi := i-band, i+band
+		if lo < 1 {
+			lo = 1
+		}
+		if hi > n-1 {
+			hi = n - 1
+		}
+		row[0] = outside
+		if i <= band {
+			row[0] = i * c.Delete
+		}
+		for j := 1; j < lo; j++ {
+			row[j] = outside
+		}
+		best := row[0]
+		for j := lo; j <= hi; j++ {
+			min := prev[j-1]
+			if ch != r2[j-1] {
+				min += c.Substitute
+			}
+			candidate := prev[j] + c.Delete
+			if candidate < min {
+				min = candidate
+			}
+			candidate = row[j-1] + c.Insert
+			if candidate < min {
+				min = candidate
+			}
+			if c.Transpose > 0 && i > 1 && j > 1 && ch == r2[j-2] && r1[i-2] == r2[j-1] {
+				candidate = prev2[j-2] + c.Transpose
+				if candidate < min {
+					min = candidate
+				}
+			}
+			if min > outside {
+				min = outside
+			}
+			row[j] = min
+			if min < best {
+				best = min
+			}
+		}
+		for j := hi + 1; j < n; j++ {
+			row[j] = outside
+		}
+		if best > max && (c.Transpose == 0 || prevBest > max) {
+			return best, false
+		}
+		prevBest = best
+		prev2, prev, row = prev, row, prev2
+	}
+	d := prev[n-1]
+	return d, d <= max
+}
+
+func appendRunes(buf []rune, s string) []rune {
+	for _, ch := range s {
+		buf = append(buf, ch)
+	}
+	return buf
+}
+
+func resize(buf []int, n int) []int {
+	if cap(buf) < n {
+		return make([]int, n)
+	}
+	return buf[:n]
+}
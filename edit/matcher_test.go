@@ -0,0 +1,105 @@
+package edit
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func randomString(r *rand.Rand) string {
+	alphabet := []rune("abcdeöß日本")
+	s := make([]rune, r.Intn(9))
+	for i := range s {
+		s[i] = alphabet[r.Intn(len(alphabet))]
+	}
+	return string(s)
+}
+
+func Test_within_agrees_with_distance(t *testing.T) {
+	costs := map[string]Costs{
+		"levenshtein": Levenshtein,
+		"indel":       Indel,
+		"damerau":     Damerau,
+		"weighted":    {Insert: 2, Delete: 1, Substitute: 3, Transpose: 1},
+	}
+	r := rand.New(rand.NewSource(1))
+	for name, c := range costs {
+		m := NewMatcher(c)
+		for i := 0; i < 2000; i++ {
+			s1, s2 := randomString(r), randomString(r)
+			max := r.Intn(6)
+			expected := c.Distance(s1, s2)
+			d, ok := m.Within(s1, s2, max)
+			if ok != (expected <= max) {
+				t.Fatalf("%s Within(%q, %q, %d)=%d, %v, want distance %d", name, s1, s2, max, d, ok, expected)
+			}
+			if ok && d != expected {
+				t.Fatalf("%s Within(%q, %q, %d)=%d, want %d", name, s1, s2, max, d, expected)
+			}
+		}
+	}
+}
+
+func Test_distance_within(t *testing.T) {
+	cases := map[string]struct {
+		s1   string
+		s2   string
+		max  int
+		dist int
+		ok   bool
+	}{
+		"within":        {"hello", "hell", 2, 1, true},
+		"at bound":      {"kitten", "sitting", 5, 5, true},
+		"beyond":        {"intention", "execution", 4, 0, false},
+		"length":        {"h", "hello", 3, 0, false},
+		"runes":         {"über", "uber", 2, 2, true},
+		"zero distance": {"日本", "日本", 0, 0, true},
+	}
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			dist, ok := DistanceWithin(tc.s1, tc.s2, tc.max)
+			if ok != tc.ok || (ok && dist != tc.dist) {
+				t.Errorf("DistanceWithin(%s, %s, %d)=%d, %v, want %d, %v", tc.s1, tc.s2, tc.max, dist, ok, tc.dist, tc.ok)
+			}
+		})
+	}
+}
+
+func Test_within_without_limit(t *testing.T) {
+	weighted := Costs{Insert: 2, Delete: 1, Substitute: 3, Transpose: 1}
+	cases := map[string]struct {
+		costs Costs
+		s1    string
+		s2    string
+		dist  int
+	}{
+		"kitten":     {Levenshtein, "kitten", "sitting", 3},
+		"empty":      {Indel, "", "", 0},
+		"inserts":    {weighted, "", "日本語", 6},
+		"deletes":    {weighted, "abcd", "", 4},
+		"substitute": {weighted, "ab", "cd", 6},
+		"transpose":  {Damerau, "ab", "ba", 1},
+	}
+	for n, tc := range cases {
+		m := NewMatcher(tc.costs)
+		if dist := m.Distance(tc.s1, tc.s2); dist != tc.dist {
+			t.Errorf("%s Distance(%q, %q)=%d, want %d", n, tc.s1, tc.s2, dist, tc.dist)
+		}
+		for _, max := range []int{math.MaxInt32, int(^uint(0) >> 1)} {
+			dist, ok := m.Within(tc.s1, tc.s2, max)
+			if !ok || dist != tc.dist {
+				t.Errorf("%s Within(%q, %q, %d)=%d, %v, want %d, true", n, tc.s1, tc.s2, max, dist, ok, tc.dist)
+			}
+		}
+	}
+}
+
+func BenchmarkDistanceWithin(b *testing.B) {
+	m := NewMatcher(Indel)
+	var dist = 0
+	for i := 0; i < b.N; i++ {
+		dist, _ = m.Within("intention", "execution", 4)
+	}
+	Dist = dist
+}
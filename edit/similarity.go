@@ -0,0 +1,114 @@
+package edit
+
+// JaroWinkler returns the Jaro-Winkler similarity of s1 and s2 between 0 for nothing in
+// common and 1 for identical strings. Strings sharing a prefix score higher which suits
+// ranking names against what has been typed so far.
+func JaroWinkler(s1, s2 string) float64 {
+	r1 := []rune(s1)
+	r2 := []rune(s2)
+	if len(r1) == 0 && len(r2) == 0 {
+		return 1
+	}
+	if len(r1) == 0 || len(r2) == 0 {
+		return 0
+	}
+
+	// runes match when equal and no further apart than half the longer string
+	window := len(r1)
+	if len(r2) > window {
+		window = len(r2)
+	}
+	window = window/2 - 1
+	if window < 0 {
+		window = 0
+	}
+
+	matched1 := make([]bool, len(r1))
+	matched2 := make([]bool, len(r2))
+	var matches int
+	for i, ch := range r1 {
+		lo, hi := i-window, i+window+1
+		if lo < 0 {
+			lo = 0
+		}
+		if hi > len(r2) {
+			hi = len(r2)
+		}
+		for j := lo; j < hi; j++ {
+			if matched2[j] || r2[j] != ch {
+				continue
+			}
+			matched1[i] = true
+			matched2[j] = true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	var transpositions, j int
+	for i, ch := range r1 {
+		if !matched1[i] {
+			continue
+		}
+		for !matched2[j] {
+			j++
+		}
+		if ch != r2[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(r1)) + m/float64(len(r2)) + (m-float64(transpositions/2))/m) / 3
+
+	var prefix int
+	for prefix < len(r1) && prefix < len(r2) && prefix < 4 && r1[prefix] == r2[prefix] {
+		prefix++
+	}
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
+
+// NGram returns the Dice coefficient of the rune n-grams of s1 and s2 between 0 for no
+// shared n-grams and 1 for identical sets. Unlike JaroWinkler it is insensitive to where
+// in the strings the shared runs of runes occur.
+func NGram(s1, s2 string, n int) float64 {
+	g1 := ngrams(s1, n)
+	g2 := ngrams(s2, n)
+	total := len(g1) + len(g2)
+	if total == 0 {
+		return 1
+	}
+
+	counts := make(map[string]int, len(g1))
+	for _, g := range g1 {
+		counts[g]++
+	}
+	var shared int
+	for _, g := range g2 {
+		if counts[g] > 0 {
+			counts[g]--
+			shared++
+		}
+	}
+	return 2 * float64(shared) / float64(total)
+}
+
+// ngrams returns the overlapping runs of n runes in s, or s itself when it is shorter.
+func ngrams(s string, n int) []string {
+	r := []rune(s)
+	if len(r) == 0 {
+		return nil
+	}
+	if len(r) <= n {
+		return []string{s}
+	}
+	grams := make([]string, 0, len(r)-n+1)
+	for i := 0; i+n <= len(r); i++ {
+		grams = append(grams, string(r[i:i+n]))
+	}
+	return grams
+}
@@ -0,0 +1,59 @@
+package edit
+
+import (
+	"math"
+	"testing"
+)
+
+func Test_jaro_winkler(t *testing.T) {
+	cases := map[string]struct {
+		s1         string
+		s2         string
+		similarity float64
+	}{
+		"identical":  {"docker", "docker", 1},
+		"martha":     {"martha", "marhta", 0.9611},
+		"dixon":      {"dixon", "dicksonx", 0.8133},
+		"disjoint":   {"abc", "xyz", 0},
+		"empty":      {"", "", 1},
+		"one empty":  {"abc", "", 0},
+		"runes":      {"größe", "große", 0.8933},
+		"japanese":   {"東京都", "東京", 0.9111},
+		"single":     {"a", "a", 1},
+		"transposed": {"ab", "ba", 0},
+	}
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			actual := JaroWinkler(tc.s1, tc.s2)
+			if math.Abs(actual-tc.similarity) > 0.0001 {
+				t.Errorf("JaroWinkler(%s, %s)=%.4f, want %.4f", tc.s1, tc.s2, actual, tc.similarity)
+			}
+		})
+	}
+}
+
+func Test_ngram(t *testing.T) {
+	cases := map[string]struct {
+		s1         string
+		s2         string
+		n          int
+		similarity float64
+	}{
+		"identical": {"docker", "docker", 2, 1},
+		"night":     {"night", "nacht", 2, 0.25},
+		"disjoint":  {"abc", "xyz", 2, 0},
+		"short":     {"a", "a", 3, 1},
+		"empty":     {"", "", 2, 1},
+		"japanese":  {"東京都", "京都府", 2, 0.5},
+	}
+	for n, tc := range cases {
+		tc := tc
+		t.Run(n, func(t *testing.T) {
+			actual := NGram(tc.s1, tc.s2, tc.n)
+			if math.Abs(actual-tc.similarity) > 0.0001 {
+				t.Errorf("NGram(%s, %s, %d)=%.4f, want %.4f", tc.s1, tc.s2, tc.n, actual, tc.similarity)
+			}
+		})
+	}
+}
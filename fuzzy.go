@@ -0,0 +1,169 @@
+package main
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/nfisher/mdindexer/edit"
+)
+
+// wordMetric measures the distance between words for fuzzy matching. It must satisfy
+// the triangle inequality for the BK-tree to find every match.
+var wordMetric = edit.Indel
+
+// bkTree is a Burkhard-Keller tree over the indexed words. Each child is keyed by its
+// edit distance from the parent so the triangle inequality prunes the branches which
+// cannot contain a word within the search radius. Removed words are left as tombstones
+// until they outnumber the live words.
+type bkTree struct {
+	root    *bkNode
+	live    int
+	deleted int
+	// longest word inserted, bounding the distance to any word in the tree
+	longest int
+	// matcher is used by insert and remove which run under the index write lock
+	matcher *edit.Matcher
+}
+
+type bkNode struct {
+	word     string
+	deleted  bool
+	children map[int]*bkNode
+	// far is the largest distance to a child, beyond it no child can be within a radius
+	far int
+}
+
+func newBKTree() *bkTree {
+	return &bkTree{matcher: edit.NewMatcher(wordMetric)}
+}
+
+// buildBKTree builds a tree containing words.
+func buildBKTree(words map[string]*WordColumn) *bkTree {
+	t := newBKTree()
+	for word := range words {
+		t.insert(word)
+	}
+	return t
+}
+
+func (t *bkTree) insert(word string) {
+	if n := utf8.RuneCountInString(word); n > t.longest {
+		t.longest = n
+	}
+	if t.root == nil {
+		t.root = &bkNode{word: word}
+		t.live++
+		return
+	}
+	node := t.root
+	for {
+		d := t.matcher.Distance(word, node.word)
+		if d == 0 {
+			if node.deleted {
+				node.deleted = false
+				t.deleted--
+				t.live++
+			}
+			return
+		}
+		child, ok := node.children[d]
+		if !ok {
+			if node.children == nil {
+				node.children = make(map[int]*bkNode)
+			}
+			node.children[d] = &bkNode{word: word}
+			if d > node.far {
+				node.far = d
+			}
+			t.live++
+			return
+		}
+		node = child
+	}
+}
+
+// remove marks word as deleted returning true when the tree should be rebuilt to shed
+// its tombstones.
+func (t *bkTree) remove(word string) bool {
+	node := t.root
+	for node != nil {
+		d := t.matcher.Distance(word, node.word)
+		if d == 0 {
+			if !node.deleted {
+				node.deleted = true
+				t.deleted++
+				t.live--
+			}
+			break
+		}
+		node = node.children[d]
+	}
+	return t.deleted > t.live
+}
+
+// nearest returns the words closest to word ordered alphabetically. Words further than
+// maxDistance are ignored, a maxDistance below zero places no limit on the distance.
+func (t *bkTree) nearest(word string, maxDistance int) Words {
+	if maxDistance >= 0 {
+		return t.search(word, maxDistance, true)
+	}
+	// widen the radius gradually so a close word prunes most of the tree
+	for radius := 2; ; radius *= 2 {
+		if radius >= utf8.RuneCountInString(word)+t.longest {
+			return t.search(word, radius, true)
+		}
+		if words := t.search(word, radius, true); len(words) > 0 {
+			return words
+		}
+	}
+}
+
+// within returns every word no further than radius from word ordered by distance then
+// alphabetically.
+func (t *bkTree) within(word string, radius int) Words {
+	words := t.search(word, radius, false)
+	sort.SliceStable(words, func(i, j int) bool {
+		return words[i].Distance < words[j].Distance
+	})
+	return words
+}
+
+// search returns the words no further than radius from word ordered alphabetically. When
+// closest is set only the words at the smallest distance found are kept.
+func (t *bkTree) search(word string, radius int, closest bool) Words {
+	if t.root == nil {
+		return nil
+	}
+	var words Words
+	m := edit.NewMatcher(wordMetric)
+	stack := []*bkNode{t.root}
+	for len(stack) > 0 {
+		last := len(stack) - 1
+		node := stack[last]
+		stack = stack[:last]
+
+		// a node further than radius beyond its furthest child cannot lead to a match
+		d, ok := m.Within(word, node.word, radius+node.far)
+		if !ok {
+			continue
+		}
+		if !node.deleted && d <= radius {
+			if closest && d != radius {
+				// a closer word shrinks the radius and discards the words found so far
+				radius = d
+				words = words[:0]
+			}
+			words = append(words, WordDist{node.word, d})
+		}
+
+		for cd, child := range node.children {
+			if cd >= d-radius && cd <= d+radius {
+				stack = append(stack, child)
+			}
+		}
+	}
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].Word < words[j].Word
+	})
+	return words
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func columns(words ...string) map[string]*WordColumn {
+	m := make(map[string]*WordColumn)
+	for _, word := range words {
+		m[word] = NewColumn(word)
+	}
+	return m
+}
+
+func Test_bk_tree_nearest(t *testing.T) {
+	cases := map[string]struct {
+		word        string
+		maxDistance int
+		expected    Words
+	}{
+		"exact":        {"docker", -1, Words{{"docker", 0}}},
+		"closest only": {"dockr", -1, Words{{"docker", 1}}},
+		"ties":         {"hell", -1, Words{{"hello", 1}, {"shell", 1}}},
+		"within limit": {"dock", 2, Words{{"docker", 2}}},
+		"beyond limit": {"kubernetes", 2, nil},
+	}
+	tree := buildBKTree(columns("docker", "dockerfile", "hello", "shell", "world", "handler"))
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			actual := tree.nearest(tc.word, tc.maxDistance)
+			if !cmp.Equal(actual, tc.expected) {
+				t.Errorf("nearest(%q, %d) mismatch (-want +got)\n%s", tc.word, tc.maxDistance, cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func Test_bk_tree_skips_removed_words(t *testing.T) {
+	tree := buildBKTree(columns("docker", "dockers", "hello"))
+	tree.remove("docker")
+
+	actual := tree.nearest("docker", -1)
+	expected := Words{{"dockers", 1}}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("nearest(`docker`) mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+
+	tree.insert("docker")
+	actual = tree.nearest("docker", -1)
+	expected = Words{{"docker", 0}}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("nearest(`docker`) after insert mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+}
+
+func Test_bk_tree_matches_linear_scan(t *testing.T) {
+	vocabulary := randomWords(2000)
+	tree := buildBKTree(vocabulary)
+	for _, needle := range []string{"index", "serach", "documnet", "zzz", "a"} {
+		actual := tree.nearest(needle, -1)
+		expected := linearNearest(vocabulary, needle)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("nearest(%q) mismatch (-want +got)\n%s", needle, cmp.Diff(expected, actual))
+		}
+	}
+}
+
+func Test_index_search_after_removing_nearest_word(t *testing.T) {
+	index := New(2)
+	index.Update(bazMD("dockers"))
+	index.Update(fooMD())
+	index.Update(bazMD("hello"))
+
+	docs, _, _ := index.SearchWith("docker", SearchOptions{MaxDistance: 8})
+	expected := DocList{{Document: "foo.md", Count: 1, Distance: 7, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.SearchWith(`docker`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
+	}
+}
+
+func Test_fuzzy_search_counts_runes_not_bytes(t *testing.T) {
+	index := New(2)
+	index.Update(&Document{Name: "de.md", WordCount: map[string]int{"über": 1}})
+	index.Update(&Document{Name: "ja.md", WordCount: map[string]int{"日本語です": 1}})
+
+	cases := map[string]DocList{
+		"uber":  {{Document: "de.md", Count: 1, Distance: 2, Rank: 1}},
+		"日本語でし": {{Document: "ja.md", Count: 1, Distance: 2, Rank: 1}},
+	}
+	for needle, expected := range cases {
+		docs, _ := index.Search(needle)
+		if !cmp.Equal(docs, expected, ignoreScore) {
+			t.Errorf("index.Search(%q) mismatch (-want +got)\n%s", needle, cmp.Diff(expected, docs, ignoreScore))
+		}
+	}
+}
+
+// linearNearest is the exhaustive scan the tree replaces.
+func linearNearest(vocabulary map[string]*WordColumn, needle string) Words {
+	var words Words
+	for word := range vocabulary {
+		d := wordMetric.Distance(needle, word)
+		if len(words) > 0 && d > words[0].Distance {
+			continue
+		}
+		if len(words) > 0 && d < words[0].Distance {
+			words = words[:0]
+		}
+		words = append(words, WordDist{word, d})
+	}
+	sort.Slice(words, func(i, j int) bool {
+		return words[i].Word < words[j].Word
+	})
+	return words
+}
+
+func randomWords(n int) map[string]*WordColumn {
+	const letters = "abcdefghijklmnopqrstuvwxyz"
+	r := rand.New(rand.NewSource(1))
+	words := make(map[string]*WordColumn, n)
+	for len(words) < n {
+		b := make([]byte, 3+r.Intn(10))
+		for i := range b {
+			b[i] = letters[r.Intn(len(letters))]
+		}
+		words[string(b)] = nil
+	}
+	return words
+}
+
+var nearestWords Words
+
+// benchmarkNeedles are a miss with no close words and a typo of a vocabulary word.
+func benchmarkNeedles(vocabulary map[string]*WordColumn) map[string]string {
+	var typo string
+	for word := range vocabulary {
+		if len(word) > 6 && (typo == "" || word < typo) {
+			typo = word
+		}
+	}
+	return map[string]string{"miss": "documnet", "typo": typo[:3] + typo[4:]}
+}
+
+func BenchmarkLinearNearest(b *testing.B) {
+	vocabulary := randomWords(50000)
+	for name, needle := range benchmarkNeedles(vocabulary) {
+		needle := needle
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				nearestWords = linearNearest(vocabulary, needle)
+			}
+		})
+	}
+}
+
+func BenchmarkBKTreeNearest(b *testing.B) {
+	vocabulary := randomWords(50000)
+	tree := buildBKTree(vocabulary)
+	for name, needle := range benchmarkNeedles(vocabulary) {
+		needle := needle
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				nearestWords = tree.nearest(needle, -1)
+			}
+		})
+	}
+}
+
+func BenchmarkBKTreeNearestWithin2(b *testing.B) {
+	vocabulary := randomWords(50000)
+	tree := buildBKTree(vocabulary)
+	for name, needle := range benchmarkNeedles(vocabulary) {
+		needle := needle
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				nearestWords = tree.nearest(needle, 2)
+			}
+		})
+	}
+}
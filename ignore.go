@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// defaultIgnoreFiles are read in every directory of a corpus unless it names its own.
+var defaultIgnoreFiles = []string{".gitignore", ".mdindexerignore"}
+
+// ignoreRule is a line of an ignore file with the semantics of .gitignore.
+type ignoreRule struct {
+	pattern string
+	// negate re-includes what an earlier rule excluded
+	negate bool
+	// dirOnly matches directories alone, the pattern ended with a slash
+	dirOnly bool
+	// anchored patterns are matched against the path relative to the ignore file,
+	// otherwise against the base name at any depth
+	anchored bool
+}
+
+// parseIgnore reads the rules of an ignore file, blank lines and comments are skipped.
+func parseIgnore(r io.Reader) []ignoreRule {
+	var rules []ignoreRule
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if !strings.HasSuffix(line, `\ `) {
+			line = strings.TrimRight(line, " \t")
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		var rule ignoreRule
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// match returns true if the rule applies to rel, a path relative to the directory of the
+// ignore file.
+func (r ignoreRule) match(rel string, dir bool) bool {
+	if r.dirOnly && !dir {
+		return false
+	}
+	if r.anchored {
+		return matchGlob(r.pattern, rel)
+	}
+	return matchGlob(r.pattern, path.Base(rel))
+}
+
+// matchGlob reports whether name matches pattern segment by segment, where ** matches any
+// number of directories.
+func matchGlob(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				// a trailing ** matches everything inside but not the directory itself
+				return len(name) > 0
+			}
+			for i := range name {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// ignores caches the rules of the ignore files in each directory of a corpus.
+type ignores struct {
+	sync.Mutex
+	rules map[string][]ignoreRule
+}
+
+// ignoreRules returns the rules read from the ignore files in dir, a path relative to the
+// corpus root.
+func (c *Corpus) ignoreRules(dir string) []ignoreRule {
+	c.ignores.Lock()
+	defer c.ignores.Unlock()
+	if rules, ok := c.ignores.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	for _, name := range c.IgnoreFiles {
+		f, err := os.Open(filepath.Join(c.Path, filepath.FromSlash(dir), name))
+		if err != nil {
+			continue
+		}
+		rules = append(rules, parseIgnore(f)...)
+		f.Close()
+	}
+	if c.ignores.rules == nil {
+		c.ignores.rules = make(map[string][]ignoreRule)
+	}
+	c.ignores.rules[dir] = rules
+	return rules
+}
+
+// forgetIgnores discards the cached rules of dir so the ignore files are read again.
+func (c *Corpus) forgetIgnores(dir string) {
+	rel, ok := c.rel(dir)
+	if !ok {
+		return
+	}
+	c.ignores.Lock()
+	delete(c.ignores.rules, rel)
+	c.ignores.Unlock()
+}
+
+// isIgnoreFile returns true if name is one of the ignore files of the corpus.
+func (c *Corpus) isIgnoreFile(name string) bool {
+	if _, ok := c.rel(name); !ok {
+		return false
+	}
+	return containsWord(c.IgnoreFiles, filepath.Base(name))
+}
+
+// ignored returns true if rel or one of the directories above it is excluded by the
+// ignore files. As with git a file cannot be re-included when its directory is excluded.
+func (c *Corpus) ignored(rel string, dir bool) bool {
+	if len(c.IgnoreFiles) == 0 || rel == "." {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	for i := range parts {
+		if c.ignoredEntry(parts[:i+1], dir || i < len(parts)-1) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredEntry applies the rules of every directory above the entry, the last rule
+// matching wins and the deepest ignore file is read last.
+func (c *Corpus) ignoredEntry(parts []string, dir bool) bool {
+	var ignored bool
+	for i := 0; i < len(parts); i++ {
+		base := strings.Join(parts[:i], "/")
+		if base == "" {
+			base = "."
+		}
+		rel := strings.Join(parts[i:], "/")
+		for _, rule := range c.ignoreRules(base) {
+			if rule.match(rel, dir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_match_glob(t *testing.T) {
+	t.Parallel()
+	cases := []struct {
+		pattern string
+		name    string
+		match   bool
+	}{
+		{"*.md", "README.md", true},
+		{"*.md", "docs/README.md", false},
+		{"docs/*.md", "docs/README.md", true},
+		{"**/*.md", "README.md", true},
+		{"**/*.md", "a/b/README.md", true},
+		{"docs/**", "docs/a/b.md", true},
+		{"docs/**", "docs", false},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+		{"[ab]?.js", "ax.js", true},
+	}
+	for _, tc := range cases {
+		actual := matchGlob(tc.pattern, tc.name)
+		if actual != tc.match {
+			t.Errorf("matchGlob(%q, %q)=%v, want %v", tc.pattern, tc.name, actual, tc.match)
+		}
+	}
+}
+
+func Test_parse_ignore(t *testing.T) {
+	t.Parallel()
+	text := "# comment\n\nnode_modules/\n/build\n!keep.md\n\\!bang\ndocs/*.tmp  \n**/gen\n"
+	expected := []ignoreRule{
+		{pattern: "node_modules", dirOnly: true},
+		{pattern: "build", anchored: true},
+		{pattern: "keep.md", negate: true},
+		{pattern: "!bang"},
+		{pattern: "docs/*.tmp", anchored: true},
+		{pattern: "**/gen", anchored: true},
+	}
+	actual := parseIgnore(strings.NewReader(text))
+	if !cmp.Equal(actual, expected, cmp.AllowUnexported(ignoreRule{})) {
+		t.Errorf("parseIgnore() mismatch (-want +got)\n%s", cmp.Diff(expected, actual, cmp.AllowUnexported(ignoreRule{})))
+	}
+}
+
+func Test_corpus_honours_ignore_files(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		".gitignore":                  "node_modules/\n/build\n*.tmp.md\n",
+		".mdindexerignore":            "drafts\n",
+		"README.md":                   "",
+		"a.tmp.md":                    "",
+		"build/out.md":                "",
+		"docs/build/guide.md":         "",
+		"docs/drafts/idea.md":         "",
+		"docs/.gitignore":             "*.md\n!keep.md\n",
+		"docs/keep.md":                "",
+		"docs/skip.md":                "",
+		"web/node_modules/lib/a.md":   "",
+		"web/.gitignore":              "!/node_modules\n",
+		".git/info/exclude.md":        "",
+		"docs/build/.mdindexerignore": "!*.tmp.md\n",
+		"docs/build/b.tmp.md":         "",
+	}
+	for name, text := range files {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		err = os.MkdirAll(filepath.Dir(filename), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = ioutil.WriteFile(filename, []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c := NewCorpus(dir, Languages{"english"})
+	docs, skipped := c.Documents()
+	if len(skipped) > 0 {
+		t.Errorf("Documents() skipped=%v, want none", skipped)
+	}
+	var actual []string
+	for _, doc := range docs {
+		rel, _ := filepath.Rel(dir, doc)
+		actual = append(actual, filepath.ToSlash(rel))
+	}
+	// /build is anchored so docs/build is walked, the deepest ignore file wins
+	expected := []string{"README.md", "docs/build/b.tmp.md", "docs/keep.md", "web/node_modules/lib/a.md"}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("Documents() mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+	// a file cannot be re-included when its directory is excluded
+	if c.Match(filepath.Join(dir, "docs", "drafts", "keep.md")) {
+		t.Errorf("Match(docs/drafts/keep.md)=true, want false")
+	}
+}
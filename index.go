@@ -2,12 +2,9 @@ package main
 
 import (
 	"fmt"
-	"log"
+	"math"
 	"sort"
-	"strings"
 	"sync"
-
-	"github.com/nfisher/mdindexer/edit"
 )
 
 var (
@@ -17,13 +14,106 @@ var (
 type Document struct {
 	Name      string
 	WordCount map[string]int
+	// Fields optionally breaks WordCount down by field, words absent from it are body text.
+	Fields map[string]FieldCounts
+	// Positions optionally records the ascending token positions of each word.
+	Positions   map[string][]int
+	Fingerprint Fingerprint
+	// Symbols optionally lists the declarations in source code.
+	Symbols []Symbol
+	// Language optionally names the language the document is written in.
+	Language string
+}
+
+// Symbol is a top level declaration in source code.
+type Symbol struct {
+	Name string
+	// Kind is one of func, method, type, const or var.
+	Kind string
+	// Receiver is the type a method is declared on.
+	Receiver string `json:",omitempty"`
+	Line     int
+}
+
+// Field identifies the part of a document a word occurs in.
+type Field int
+
+const (
+	FieldBody Field = iota
+	FieldTitle
+	FieldHeading
+	FieldCode
+	FieldTag
+	// FieldIdent is a whole identifier in source code.
+	FieldIdent
+	// FieldSubword is a camelCase or snake_case part of an identifier.
+	FieldSubword
+	// FieldComment is a word in a source code comment.
+	FieldComment
+	// FieldString is a word in a source code string literal.
+	FieldString
+)
+
+// fieldCount is the number of fields, an int constant so it can size the generated msgp arrays.
+const fieldCount = int(FieldString) + 1
+
+// FieldCounts holds the number of times a word occurs in each field.
+type FieldCounts [fieldCount]int
+
+// fieldWeights is how much an occurrence in each field contributes to relevance.
+var fieldWeights = FieldCounts{
+	FieldBody:    1,
+	FieldTitle:   8,
+	FieldHeading: 4,
+	FieldCode:    1,
+	FieldTag:     6,
+	FieldIdent:   2,
+	FieldSubword: 1,
+	FieldComment: 1,
+	FieldString:  1,
+}
+
+// Weight returns the field weighted number of occurrences.
+func (f FieldCounts) Weight() int {
+	var w int
+	for i, count := range f {
+		w += count * fieldWeights[i]
+	}
+	return w
+}
+
+// Total returns the number of occurrences across all fields.
+func (f FieldCounts) Total() int {
+	var total int
+	for _, count := range f {
+		total += count
+	}
+	return total
+}
+
+// Fingerprint identifies the version of a file that was indexed.
+type Fingerprint struct {
+	ModTime int64
+	Size    int64
+	Hash    uint64
+}
+
+// Matches returns true when the modification time and size are unchanged.
+func (f Fingerprint) Matches(modTime int64, size int64) bool {
+	return f.ModTime == modTime && f.Size == size
 }
 
 // New creates an index that can accommodate the number of documents specified by size.
 func New(size int) *Index {
 	return &Index{
-		Words: make(map[string]*WordColumn),
-		Names: make([]string, 0, size),
+		Words:        make(map[string]*WordColumn),
+		Names:        make([]string, 0, size),
+		Fingerprints: make([]Fingerprint, 0, size),
+		Lengths:      make([]int, 0, size),
+		Symbols:      make([][]Symbol, 0, size),
+		Languages:    make([]string, 0, size),
+		ids:          make(map[string]int, size),
+		fuzzy:        newBKTree(),
 	}
 }
 
@@ -31,7 +121,32 @@ func New(size int) *Index {
 type Index struct {
 	Words        map[string]*WordColumn
 	Names        []string
+	Fingerprints []Fingerprint
+	// number of words in each document used to normalise relevance
+	Lengths []int
+	// positions in Names vacated by Remove and available for reuse
+	Free []int
+	// declarations in each document
+	Symbols [][]Symbol
+	// language of each document, empty if unknown
+	Languages    []string
 	sync.RWMutex `msg:"-"`
+	// position of each name, rebuilt by rebuild after a read from file
+	ids map[string]int
+	// sum of Lengths, rebuilt by rebuild after a read from file
+	totalLength int
+	// analyzers documents were read with, applied to query terms so they match
+	analyzers []*Analyzer
+	// hash of the language settings documents were read with, saved in the file header
+	analysis uint64
+	// languages documents were read as, used to recognise lang filters
+	languages *Registry
+	// sorted words for prefix, suffix and wildcard expansion
+	terms termDictionary
+	// words by edit distance for fuzzy matching, rebuilt by rebuild after a read from file
+	fuzzy *bkTree
+	// declarations by name, rebuilt on first use after documents change
+	definitions symbolTable
 }
 
 // Capacity returns the number of documents in the index.
@@ -41,6 +156,102 @@ func (z *Index) Capacity() int {
 	return cap(z.Names)
 }
 
+// SetAnalyzers records the analyzers documents were read with so query terms are
+// analyzed the same ways.
+func (z *Index) SetAnalyzers(analyzers ...*Analyzer) {
+	z.Lock()
+	defer z.Unlock()
+	z.analyzers = analyzers
+}
+
+// SetLanguages records the languages documents are read as so queries can filter by them.
+func (z *Index) SetLanguages(languages *Registry) {
+	z.Lock()
+	defer z.Unlock()
+	z.languages = languages
+}
+
+// Registry returns the languages documents are read as, the built in ones unless set.
+func (z *Index) Registry() *Registry {
+	z.RLock()
+	defer z.RUnlock()
+	if z.languages == nil {
+		return builtinLanguages
+	}
+	return z.languages
+}
+
+// Analysis returns the hash of the language settings documents were read with.
+func (z *Index) Analysis() uint64 {
+	z.RLock()
+	defer z.RUnlock()
+	return z.analysis
+}
+
+// SetAnalysis records the hash of the language settings documents are read with so an
+// index saved with other settings can be detected and rebuilt.
+func (z *Index) SetAnalysis(analysis uint64) {
+	z.Lock()
+	defer z.Unlock()
+	z.analysis = analysis
+}
+
+// Ignored returns true if word is removed by every analyzer, such as a stop word.
+func (z *Index) Ignored(word string) bool {
+	z.RLock()
+	defer z.RUnlock()
+	return len(z.forms(word)) == 0
+}
+
+// Forms returns the distinct analyzed forms of a query word, none for words which are
+// never indexed.
+func (z *Index) Forms(word string) []string {
+	z.RLock()
+	defer z.RUnlock()
+	return z.forms(word)
+}
+
+// forms returns the distinct analyzed forms of a query word, none for words which are
+// never indexed.
+func (z *Index) forms(word string) []string {
+	var forms []string
+	for _, term := range z.termFuncs() {
+		if t := term(word); t != "" && !containsWord(forms, t) {
+			forms = append(forms, t)
+		}
+	}
+	return forms
+}
+
+// termFuncs returns each way query terms are analyzed, the terms are used as typed when
+// no analyzer was set.
+func (z *Index) termFuncs() []TokenFilter {
+	if len(z.analyzers) == 0 {
+		return []TokenFilter{func(word string) string { return word }}
+	}
+	fns := make([]TokenFilter, 0, len(z.analyzers))
+	for _, a := range z.analyzers {
+		fns = append(fns, a.Term)
+	}
+	return fns
+}
+
+func containsWord(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of documents currently indexed.
+func (z *Index) Len() int {
+	z.RLock()
+	defer z.RUnlock()
+	return len(z.Names) - len(z.Free)
+}
+
 // WordCount provides the number of Words in the index.
 func (z *Index) WordCount() int {
 	z.RLock()
@@ -55,28 +266,197 @@ func (z *Index) Update(doc *Document) {
 	var isNew bool
 	pos := z.byName(doc.Name)
 	if pos == nameNotFound {
-		pos = len(z.Names)
-		z.Names = append(z.Names, doc.Name)
+		pos = z.allocate(doc.Name)
 		isNew = true
 	}
+	for len(z.Fingerprints) <= pos {
+		z.Fingerprints = append(z.Fingerprints, Fingerprint{})
+	}
+	for len(z.Lengths) <= pos {
+		z.Lengths = append(z.Lengths, 0)
+	}
+	for len(z.Symbols) <= pos {
+		z.Symbols = append(z.Symbols, nil)
+	}
+	for len(z.Languages) <= pos {
+		z.Languages = append(z.Languages, "")
+	}
+	z.Languages[pos] = doc.Language
 
+	// identical content only needs the new modification time recorded
+	prev := z.Fingerprints[pos]
+	z.Fingerprints[pos] = doc.Fingerprint
+	if !isNew && prev.Hash != 0 && prev.Hash == doc.Fingerprint.Hash {
+		return
+	}
+	if len(doc.Symbols) > 0 || len(z.Symbols[pos]) > 0 {
+		z.Symbols[pos] = doc.Symbols
+		z.definitions.invalidate()
+	}
+
+	var length int
 	cur := make(map[string]bool)
 	for word, count := range doc.WordCount {
+		length += count
 		cur[word] = true
 		col, ok := z.Words[word]
 		if !ok {
 			col = NewColumn(word)
+			z.terms.invalidate()
+			z.fuzzy.insert(word)
 		}
-		col.Upsert(pos, count)
+		fields, ok := doc.Fields[word]
+		if !ok {
+			fields = FieldCounts{FieldBody: count}
+		}
+		col.Upsert(Posting{
+			Doc:       pos,
+			Count:     count,
+			Fields:    fields,
+			Positions: encodePositions(doc.Positions[word]),
+		})
 		z.Words[word] = col
 	}
+	z.totalLength += length - z.Lengths[pos]
+	z.Lengths[pos] = length
 
 	if !isNew {
 		z.clean(pos, cur)
 	}
 }
 
+// allocate assigns name a position reusing a vacated one where possible.
+func (z *Index) allocate(name string) int {
+	var pos int
+	if last := len(z.Free) - 1; last >= 0 {
+		pos = z.Free[last]
+		z.Free = z.Free[:last]
+		z.Names[pos] = name
+	} else {
+		pos = len(z.Names)
+		z.Names = append(z.Names, name)
+	}
+	z.ids[name] = pos
+	return pos
+}
+
+// Remove deletes the named document from every column returning false if it was not
+// indexed. The documents position is reused by the next new document.
+func (z *Index) Remove(name string) bool {
+	z.Lock()
+	defer z.Unlock()
+	pos := z.byName(name)
+	if pos == nameNotFound {
+		return false
+	}
+	z.clean(pos, nil)
+	z.Names[pos] = ""
+	delete(z.ids, name)
+	if pos < len(z.Fingerprints) {
+		z.Fingerprints[pos] = Fingerprint{}
+	}
+	if pos < len(z.Lengths) {
+		z.totalLength -= z.Lengths[pos]
+		z.Lengths[pos] = 0
+	}
+	if pos < len(z.Symbols) && len(z.Symbols[pos]) > 0 {
+		z.Symbols[pos] = nil
+		z.definitions.invalidate()
+	}
+	if pos < len(z.Languages) {
+		z.Languages[pos] = ""
+	}
+	z.Free = append(z.Free, pos)
+	return true
+}
+
+// Compact renumbers the documents so no positions are vacant.
+func (z *Index) Compact() {
+	z.Lock()
+	defer z.Unlock()
+	if len(z.Free) == 0 {
+		return
+	}
+
+	moved := make(map[int]int, len(z.Names))
+	names := make([]string, 0, len(z.Names)-len(z.Free))
+	fingerprints := make([]Fingerprint, 0, cap(names))
+	lengths := make([]int, 0, cap(names))
+	symbols := make([][]Symbol, 0, cap(names))
+	languages := make([]string, 0, cap(names))
+	for pos, name := range z.Names {
+		if name == "" {
+			continue
+		}
+		moved[pos] = len(names)
+		names = append(names, name)
+		fingerprints = append(fingerprints, z.Fingerprints[pos])
+		lengths = append(lengths, z.Lengths[pos])
+		var syms []Symbol
+		if pos < len(z.Symbols) {
+			syms = z.Symbols[pos]
+		}
+		symbols = append(symbols, syms)
+		var lang string
+		if pos < len(z.Languages) {
+			lang = z.Languages[pos]
+		}
+		languages = append(languages, lang)
+	}
+
+	for _, col := range z.Words {
+		for i := range col.Docs {
+			col.Docs[i].Doc = moved[col.Docs[i].Doc]
+		}
+		col.lazyIndex()
+	}
+	z.Names = names
+	z.Fingerprints = fingerprints
+	z.Lengths = lengths
+	z.Symbols = symbols
+	z.Languages = languages
+	z.Free = nil
+	z.rebuild()
+}
+
+// Language returns the language recorded when the named document was indexed.
+func (z *Index) Language(name string) string {
+	z.RLock()
+	defer z.RUnlock()
+	pos := z.byName(name)
+	if pos == nameNotFound || pos >= len(z.Languages) {
+		return ""
+	}
+	return z.Languages[pos]
+}
+
+// Fingerprint returns the fingerprint recorded when the named document was indexed.
+func (z *Index) Fingerprint(name string) (Fingerprint, bool) {
+	z.RLock()
+	defer z.RUnlock()
+	pos := z.byName(name)
+	if pos == nameNotFound || pos >= len(z.Fingerprints) {
+		return Fingerprint{}, false
+	}
+	return z.Fingerprints[pos], true
+}
+
+// Documents returns the names of all documents in the index.
+func (z *Index) Documents() []string {
+	z.RLock()
+	defer z.RUnlock()
+	names := make([]string, 0, len(z.Names))
+	for _, name := range z.Names {
+		if name == "" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 func (z *Index) clean(pos int, cur map[string]bool) {
+	var shed bool
 	for word, col := range z.Words {
 		if cur[word] {
 			continue
@@ -84,81 +464,138 @@ func (z *Index) clean(pos int, cur map[string]bool) {
 		col.Remove(pos)
 		if col.Empty() {
 			delete(z.Words, word)
+			z.terms.invalidate()
+			shed = z.fuzzy.remove(word) || shed
 		}
 	}
+	if shed {
+		z.fuzzy = buildBKTree(z.Words)
+	}
 }
 
-// Search returns the list of documents that contain needle.
+// Search returns the list of documents that contain needle or the closest words to it
+// using the default options.
 func (z *Index) Search(needle string) (DocList, error) {
+	docs, _, err := z.SearchWith(needle, DefaultSearchOptions)
+	return docs, err
+}
+
+// SearchWith returns the list of documents that contain needle and the words matched.
+// When neither needle nor its analyzed form are indexed the closest words within the
+// options maximum distance are matched instead.
+func (z *Index) SearchWith(needle string, opts SearchOptions) (DocList, Words, error) {
 	z.RLock()
 	defer z.RUnlock()
 	if 1 > len(z.Words) {
-		return nil, ErrWordNotIndexed
+		return nil, nil, ErrWordNotIndexed
 	}
-	var words = Words{{needle, 0}}
-	_, ok := z.Words[needle]
-	if !ok {
-		words = Words{}
-		for k := range z.Words {
-			d := edit.Distance2(needle, k)
-			words = append(words, WordDist{k, d})
+	// the needle matches both as typed and in its analyzed form so "building" finds the
+	// stem "build" in prose and the identifier "building" in code
+	var words Words
+	var matched []string
+	forms := z.forms(needle)
+	for _, form := range append([]string{needle}, forms...) {
+		if _, ok := z.Words[form]; ok && !containsWord(matched, form) {
+			matched = append(matched, form)
+			words = append(words, WordDist{form, 0})
 		}
-		sort.Sort(words)
-		end := len(words)
-		min := words[0].Distance
-		for i := 1; i < len(words); i++ {
-			if words[i].Distance > min {
-				break
+	}
+
+	maxDistance := opts.maxDistance(needle)
+	switch {
+	case maxDistance == 0 || len(forms) == 0:
+	case len(words) > 0 && opts.AlwaysExpand:
+		for _, word := range z.fuzzy.within(needle, maxDistance) {
+			if word.Distance > 0 && !containsWord(forms, word.Word) {
+				words = append(words, word)
 			}
-			end = i
 		}
-		words = words[0:end]
+	case len(words) == 0:
+		words = z.fuzzy.nearest(needle, maxDistance)
 	}
 
 	pos := make(map[string]int)
 	var docs = make(DocList, 0, len(words))
 	for _, word := range words {
 		col := z.Words[word.Word]
-		col.Apply(func(id int, count int) {
-			if count > 0 {
-				doc := z.byId(id)
-				relevance := DocRelevance{Document: doc}
-				relevance.Count = count
-				relevance.Distance = word.Distance
-				p, ok := pos[doc]
-				if !ok {
-					p = len(docs)
-					docs = append(docs, relevance)
-					pos[doc] = p
-					return
-				}
-				if docs[p].Distance < relevance.Distance {
-					return
-				}
-
-				docs[p].Distance = relevance.Distance
-				docs[p].Count = relevance.Count
+		col.Apply(func(p Posting) {
+			doc := z.byId(p.Doc)
+			relevance := DocRelevance{Document: doc}
+			relevance.Count = p.Count
+			relevance.Distance = word.Distance
+			relevance.Rank = p.Fields.Weight()
+			relevance.Score = z.bm25(p, len(col.Docs))
+			i, ok := pos[doc]
+			if !ok {
+				pos[doc] = len(docs)
+				docs = append(docs, relevance)
+				return
+			}
+			if docs[i].Distance < relevance.Distance {
+				return
 			}
+			docs[i] = relevance
 		})
 	}
 
-	sort.Slice(docs, func(i, j int) bool {
-		return docs[i].Count < docs[j].Count
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score > docs[j].Score
 	})
 
-	return docs, nil
+	return docs, words, nil
+}
+
+const (
+	// bm25K1 controls how quickly repeated occurrences of a word saturate.
+	bm25K1 = 1.2
+	// bm25B controls how strongly scores are normalised by document length.
+	bm25B = 0.75
+)
+
+// bm25 scores the posting of a word that occurs in docFreq documents using the field
+// weighted occurrences as the term frequency.
+func (z *Index) bm25(p Posting, docFreq int) float64 {
+	n := float64(len(z.Names) - len(z.Free))
+	df := float64(docFreq)
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+
+	norm := 1.0
+	avg := float64(z.totalLength) / n
+	if avg > 0 {
+		norm = 1 - bm25B + bm25B*float64(z.Lengths[p.Doc])/avg
+	}
+	tf := float64(p.Fields.Weight())
+	return idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
 }
 
 const nameNotFound = -1
 
-func (z *Index) byName(name string) int {
-	var id int
-	for ; id < len(z.Names); id++ {
-		if name == z.Names[id] {
-			return id
+// rebuild restores the unexported state derived from the serialised fields.
+func (z *Index) rebuild() {
+	z.ids = make(map[string]int, len(z.Names))
+	for id, name := range z.Names {
+		if name != "" {
+			z.ids[name] = id
 		}
 	}
-	return nameNotFound
+	z.totalLength = 0
+	for _, length := range z.Lengths {
+		z.totalLength += length
+	}
+	for _, col := range z.Words {
+		col.lazyIndex()
+	}
+	z.terms.invalidate()
+	z.fuzzy = buildBKTree(z.Words)
+	z.definitions.invalidate()
+}
+
+func (z *Index) byName(name string) int {
+	id, ok := z.ids[name]
+	if !ok {
+		return nameNotFound
+	}
+	return id
 }
 
 func (z *Index) byId(id int) string {
@@ -169,136 +606,128 @@ func (z *Index) byId(id int) string {
 func NewColumn(name string) *WordColumn {
 	return &WordColumn{
 		Name: name,
-		Docs: make([][2]int, 0, 64),
+		Docs: make([]Posting, 0, 64),
 	}
 }
 
 // WordColumn maintains the frequency a word occurs in the named document.
 type WordColumn struct {
 	Name string
-	Docs [][2]int
+	Docs []Posting
 	// msgpack/json can't serialise map with int keys
 	idx map[int]int
 }
 
-func (z *WordColumn) Upsert(pos int, count int) {
-	// lazily build the index so a read from file does not break
-	if z.idx == nil {
-		z.lazyIndex()
-	}
-
-	i, ok := z.idx[pos]
+// Posting records the occurrences of a word in the document at position Doc.
+type Posting struct {
+	Doc    int
+	Count  int
+	Fields FieldCounts
+	// Positions are the delta encoded token positions, see encodePositions.
+	Positions []byte
+}
 
+func (z *WordColumn) Upsert(p Posting) {
 	// make a sparse matrices, don't store count < 1
-	if count < 1 {
+	if p.Count < 1 {
+		z.Remove(p.Doc)
 		return
 	}
 
-	tup := [2]int{pos, count}
+	// lazily build the index so a read from file does not break
+	if z.idx == nil {
+		z.lazyIndex()
+	}
+
+	i, ok := z.idx[p.Doc]
 	if !ok {
-		i := len(z.Docs)
-		z.idx[pos] = i
-		z.Docs = append(z.Docs, tup)
+		z.idx[p.Doc] = len(z.Docs)
+		z.Docs = append(z.Docs, p)
 		return
 	}
-	z.Docs[i] = tup
+	z.Docs[i] = p
 }
 
 func (z *WordColumn) lazyIndex() {
 	z.idx = make(map[int]int)
-	for i, tup := range z.Docs {
-		pos := tup[0]
-		z.idx[pos] = i
+	for i, p := range z.Docs {
+		z.idx[p.Doc] = i
 	}
 }
 
-func (z *WordColumn) Apply(each func(int, int)) {
-	for _, tup := range z.Docs {
-		if tup[1] > 0 {
-			each(tup[0], tup[1])
+// find returns the posting for the document at pos without modifying the column so it
+// is safe to call while holding a read lock.
+func (z *WordColumn) find(pos int) (Posting, bool) {
+	if z.idx != nil {
+		i, ok := z.idx[pos]
+		if !ok {
+			return Posting{}, false
 		}
+		return z.Docs[i], true
+	}
+	for _, p := range z.Docs {
+		if p.Doc == pos {
+			return p, true
+		}
+	}
+	return Posting{}, false
+}
+
+func (z *WordColumn) Apply(each func(Posting)) {
+	for _, p := range z.Docs {
+		each(p)
 	}
 }
 
 func (z *WordColumn) Empty() bool {
-	var count int
-	z.Apply(func(i int, i2 int) {
-		count++
-	})
-	return count == 0
+	return len(z.Docs) == 0
 }
 
 func (z *WordColumn) Remove(pos int) {
+	if z.idx == nil {
+		z.lazyIndex()
+	}
+
 	i, ok := z.idx[pos]
 	if !ok {
 		return
 	}
 	delete(z.idx, pos)
-	// TODO: should consider compacting the array instead of using 0
-	z.Docs[i] = [2]int{pos, 0}
-}
-
-// Search executes the query against the index returning a document list.
-func Search(query string, index *Index) ScoreList {
-	var result = make(Scores)
-	if query == "" {
-		return ScoreList{}
-	}
-
-	query = strings.ToLower(query)
-	terms := strings.Split(query, " ")
-	union := make(StrSet)
-	for i, term := range terms {
-		docs, err := index.Search(term)
-		if err != nil {
-			log.Printf("search=failed query=%s error='%v'\n", term, err)
-			continue
-		}
 
-		s := make(StrSet)
-		for _, d := range docs {
-			n := d.Document
-			if i == 0 {
-				result[n] = d.Distance + d.Rank
-				s[n] = true
-				continue
-			}
-			if !union[n] {
-				continue
-			}
-			result[n] += d.Distance + d.Rank
-			s[n] = true
-		}
-		union = s
+	// keep the column dense by moving the last posting into the vacated slot
+	last := len(z.Docs) - 1
+	if i != last {
+		z.Docs[i] = z.Docs[last]
+		z.idx[z.Docs[i].Doc] = i
 	}
+	z.Docs = z.Docs[:last]
+}
 
-	list := make(ScoreList, 0, len(union))
-	for n := range result {
-		if !union[n] {
-			continue
-		}
-		length := len(n) - len(query)
-		dist := edit.Distance2(query, n) - length
-		list = append(list, Score{Document: n, Rank: result[n], NameDistance: dist})
+// Search executes the query against the index returning a document list. See ParseQuery
+// for the query syntax.
+func Search(query string, index *Index) (ScoreList, error) {
+	result, err := SearchWith(query, index, DefaultSearchOptions)
+	if err != nil {
+		return nil, err
 	}
-	sort.Slice(list, func(i, j int) bool {
-		return list[i].NameDistance < list[j].NameDistance
-	})
-	sort.SliceStable(list, func(i, j int) bool {
-		return list[i].Rank < list[j].Rank
-	})
+	return result.Docs, nil
+}
 
-	return list
+// fuzzyPenalty discounts the score of words matched by edit distance rather than exactly.
+func fuzzyPenalty(distance int) float64 {
+	return 1 / float64(1+distance)
 }
 
 type Score struct {
-	Document     string
-	Rank         int
-	NameDistance int
+	Document string
+	Score    float64
+	Distance int
+	// NameSimilarity is how closely the documents file name resembles the query from 0 to 1.
+	NameSimilarity float64
 }
 type ScoreList []Score
 
-type Scores map[string]int
+type Scores map[string]float64
 
 type StrSet map[string]bool
 
@@ -319,6 +748,7 @@ type DocRelevance struct {
 	Count    int
 	Distance int
 	Rank     int
+	Score    float64
 }
 
 type WordDist struct {
@@ -135,6 +135,12 @@ func (z *DocRelevance) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Rank")
 				return
 			}
+		case "Score":
+			z.Score, err = dc.ReadFloat64()
+			if err != nil {
+				err = msgp.WrapError(err, "Score")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -148,9 +154,9 @@ func (z *DocRelevance) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *DocRelevance) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 4
+	// map header, size 5
 	// write "Document"
-	err = en.Append(0x84, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
+	err = en.Append(0x85, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
 	if err != nil {
 		return
 	}
@@ -189,15 +195,25 @@ func (z *DocRelevance) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Rank")
 		return
 	}
+	// write "Score"
+	err = en.Append(0xa5, 0x53, 0x63, 0x6f, 0x72, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteFloat64(z.Score)
+	if err != nil {
+		err = msgp.WrapError(err, "Score")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *DocRelevance) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 4
+	// map header, size 5
 	// string "Document"
-	o = append(o, 0x84, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
+	o = append(o, 0x85, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
 	o = msgp.AppendString(o, z.Document)
 	// string "Count"
 	o = append(o, 0xa5, 0x43, 0x6f, 0x75, 0x6e, 0x74)
@@ -208,6 +224,9 @@ func (z *DocRelevance) MarshalMsg(b []byte) (o []byte, err error) {
 	// string "Rank"
 	o = append(o, 0xa4, 0x52, 0x61, 0x6e, 0x6b)
 	o = msgp.AppendInt(o, z.Rank)
+	// string "Score"
+	o = append(o, 0xa5, 0x53, 0x63, 0x6f, 0x72, 0x65)
+	o = msgp.AppendFloat64(o, z.Score)
 	return
 }
 
@@ -253,6 +272,12 @@ func (z *DocRelevance) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Rank")
 				return
 			}
+		case "Score":
+			z.Score, bts, err = msgp.ReadFloat64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Score")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -267,7 +292,7 @@ func (z *DocRelevance) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *DocRelevance) Msgsize() (s int) {
-	s = 1 + 9 + msgp.StringPrefixSize + len(z.Document) + 6 + msgp.IntSize + 9 + msgp.IntSize + 5 + msgp.IntSize
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.Document) + 6 + msgp.IntSize + 9 + msgp.IntSize + 5 + msgp.IntSize + 6 + msgp.Float64Size
 	return
 }
 
@@ -325,6 +350,157 @@ func (z *Document) DecodeMsg(dc *msgp.Reader) (err error) {
 				}
 				z.WordCount[za0001] = za0002
 			}
+		case "Fields":
+			var zb0003 uint32
+			zb0003, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Fields")
+				return
+			}
+			if z.Fields == nil {
+				z.Fields = make(map[string]FieldCounts, zb0003)
+			} else if len(z.Fields) > 0 {
+				for key := range z.Fields {
+					delete(z.Fields, key)
+				}
+			}
+			for zb0003 > 0 {
+				zb0003--
+				var za0003 string
+				var za0004 FieldCounts
+				za0003, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Fields")
+					return
+				}
+				var zb0004 uint32
+				zb0004, err = dc.ReadArrayHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Fields", za0003)
+					return
+				}
+				if zb0004 != uint32(fieldCount) {
+					err = msgp.ArrayError{Wanted: uint32(fieldCount), Got: zb0004}
+					return
+				}
+				for za0005 := range za0004 {
+					za0004[za0005], err = dc.ReadInt()
+					if err != nil {
+						err = msgp.WrapError(err, "Fields", za0003, za0005)
+						return
+					}
+				}
+				z.Fields[za0003] = za0004
+			}
+		case "Positions":
+			var zb0005 uint32
+			zb0005, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Positions")
+				return
+			}
+			if z.Positions == nil {
+				z.Positions = make(map[string][]int, zb0005)
+			} else if len(z.Positions) > 0 {
+				for key := range z.Positions {
+					delete(z.Positions, key)
+				}
+			}
+			for zb0005 > 0 {
+				zb0005--
+				var za0006 string
+				var za0007 []int
+				za0006, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Positions")
+					return
+				}
+				var zb0006 uint32
+				zb0006, err = dc.ReadArrayHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Positions", za0006)
+					return
+				}
+				if cap(za0007) >= int(zb0006) {
+					za0007 = (za0007)[:zb0006]
+				} else {
+					za0007 = make([]int, zb0006)
+				}
+				for za0008 := range za0007 {
+					za0007[za0008], err = dc.ReadInt()
+					if err != nil {
+						err = msgp.WrapError(err, "Positions", za0006, za0008)
+						return
+					}
+				}
+				z.Positions[za0006] = za0007
+			}
+		case "Fingerprint":
+			var zb0007 uint32
+			zb0007, err = dc.ReadMapHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Fingerprint")
+				return
+			}
+			for zb0007 > 0 {
+				zb0007--
+				field, err = dc.ReadMapKeyPtr()
+				if err != nil {
+					err = msgp.WrapError(err, "Fingerprint")
+					return
+				}
+				switch msgp.UnsafeString(field) {
+				case "ModTime":
+					z.Fingerprint.ModTime, err = dc.ReadInt64()
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint", "ModTime")
+						return
+					}
+				case "Size":
+					z.Fingerprint.Size, err = dc.ReadInt64()
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint", "Size")
+						return
+					}
+				case "Hash":
+					z.Fingerprint.Hash, err = dc.ReadUint64()
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint", "Hash")
+						return
+					}
+				default:
+					err = dc.Skip()
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint")
+						return
+					}
+				}
+			}
+		case "Symbols":
+			var zb0008 uint32
+			zb0008, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Symbols")
+				return
+			}
+			if cap(z.Symbols) >= int(zb0008) {
+				z.Symbols = (z.Symbols)[:zb0008]
+			} else {
+				z.Symbols = make([]Symbol, zb0008)
+			}
+			for za0009 := range z.Symbols {
+				err = z.Symbols[za0009].DecodeMsg(dc)
+				if err != nil {
+					err = msgp.WrapError(err, "Symbols", za0009)
+					return
+				}
+			}
+		case "Language":
+			z.Language, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Language")
+				return
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -338,9 +514,9 @@ func (z *Document) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *Document) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 2
+	// map header, size 7
 	// write "Name"
-	err = en.Append(0x82, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	err = en.Append(0x87, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
@@ -371,15 +547,136 @@ func (z *Document) EncodeMsg(en *msgp.Writer) (err error) {
 			return
 		}
 	}
+	// write "Fields"
+	err = en.Append(0xa6, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.Fields)))
+	if err != nil {
+		err = msgp.WrapError(err, "Fields")
+		return
+	}
+	for za0003, za0004 := range z.Fields {
+		err = en.WriteString(za0003)
+		if err != nil {
+			err = msgp.WrapError(err, "Fields")
+			return
+		}
+		err = en.WriteArrayHeader(uint32(fieldCount))
+		if err != nil {
+			err = msgp.WrapError(err, "Fields", za0003)
+			return
+		}
+		for za0005 := range za0004 {
+			err = en.WriteInt(za0004[za0005])
+			if err != nil {
+				err = msgp.WrapError(err, "Fields", za0003, za0005)
+				return
+			}
+		}
+	}
+	// write "Positions"
+	err = en.Append(0xa9, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.Positions)))
+	if err != nil {
+		err = msgp.WrapError(err, "Positions")
+		return
+	}
+	for za0006, za0007 := range z.Positions {
+		err = en.WriteString(za0006)
+		if err != nil {
+			err = msgp.WrapError(err, "Positions")
+			return
+		}
+		err = en.WriteArrayHeader(uint32(len(za0007)))
+		if err != nil {
+			err = msgp.WrapError(err, "Positions", za0006)
+			return
+		}
+		for za0008 := range za0007 {
+			err = en.WriteInt(za0007[za0008])
+			if err != nil {
+				err = msgp.WrapError(err, "Positions", za0006, za0008)
+				return
+			}
+		}
+	}
+	// write "Fingerprint"
+	err = en.Append(0xab, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	// map header, size 3
+	// write "ModTime"
+	err = en.Append(0x83, 0xa7, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Fingerprint.ModTime)
+	if err != nil {
+		err = msgp.WrapError(err, "Fingerprint", "ModTime")
+		return
+	}
+	// write "Size"
+	err = en.Append(0xa4, 0x53, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Fingerprint.Size)
+	if err != nil {
+		err = msgp.WrapError(err, "Fingerprint", "Size")
+		return
+	}
+	// write "Hash"
+	err = en.Append(0xa4, 0x48, 0x61, 0x73, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Fingerprint.Hash)
+	if err != nil {
+		err = msgp.WrapError(err, "Fingerprint", "Hash")
+		return
+	}
+	// write "Symbols"
+	err = en.Append(0xa7, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Symbols)))
+	if err != nil {
+		err = msgp.WrapError(err, "Symbols")
+		return
+	}
+	for za0009 := range z.Symbols {
+		err = z.Symbols[za0009].EncodeMsg(en)
+		if err != nil {
+			err = msgp.WrapError(err, "Symbols", za0009)
+			return
+		}
+	}
+	// write "Language"
+	err = en.Append(0xa8, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Language)
+	if err != nil {
+		err = msgp.WrapError(err, "Language")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
 func (z *Document) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 2
+	// map header, size 7
 	// string "Name"
-	o = append(o, 0x82, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = append(o, 0x87, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
 	o = msgp.AppendString(o, z.Name)
 	// string "WordCount"
 	o = append(o, 0xa9, 0x57, 0x6f, 0x72, 0x64, 0x43, 0x6f, 0x75, 0x6e, 0x74)
@@ -388,6 +685,51 @@ func (z *Document) MarshalMsg(b []byte) (o []byte, err error) {
 		o = msgp.AppendString(o, za0001)
 		o = msgp.AppendInt(o, za0002)
 	}
+	// string "Fields"
+	o = append(o, 0xa6, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73)
+	o = msgp.AppendMapHeader(o, uint32(len(z.Fields)))
+	for za0003, za0004 := range z.Fields {
+		o = msgp.AppendString(o, za0003)
+		o = msgp.AppendArrayHeader(o, uint32(fieldCount))
+		for za0005 := range za0004 {
+			o = msgp.AppendInt(o, za0004[za0005])
+		}
+	}
+	// string "Positions"
+	o = append(o, 0xa9, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	o = msgp.AppendMapHeader(o, uint32(len(z.Positions)))
+	for za0006, za0007 := range z.Positions {
+		o = msgp.AppendString(o, za0006)
+		o = msgp.AppendArrayHeader(o, uint32(len(za0007)))
+		for za0008 := range za0007 {
+			o = msgp.AppendInt(o, za0007[za0008])
+		}
+	}
+	// string "Fingerprint"
+	o = append(o, 0xab, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74)
+	// map header, size 3
+	// string "ModTime"
+	o = append(o, 0x83, 0xa7, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.Fingerprint.ModTime)
+	// string "Size"
+	o = append(o, 0xa4, 0x53, 0x69, 0x7a, 0x65)
+	o = msgp.AppendInt64(o, z.Fingerprint.Size)
+	// string "Hash"
+	o = append(o, 0xa4, 0x48, 0x61, 0x73, 0x68)
+	o = msgp.AppendUint64(o, z.Fingerprint.Hash)
+	// string "Symbols"
+	o = append(o, 0xa7, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Symbols)))
+	for za0009 := range z.Symbols {
+		o, err = z.Symbols[za0009].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Symbols", za0009)
+			return
+		}
+	}
+	// string "Language"
+	o = append(o, 0xa8, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65)
+	o = msgp.AppendString(o, z.Language)
 	return
 }
 
@@ -445,6 +787,157 @@ func (z *Document) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				}
 				z.WordCount[za0001] = za0002
 			}
+		case "Fields":
+			var zb0003 uint32
+			zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Fields")
+				return
+			}
+			if z.Fields == nil {
+				z.Fields = make(map[string]FieldCounts, zb0003)
+			} else if len(z.Fields) > 0 {
+				for key := range z.Fields {
+					delete(z.Fields, key)
+				}
+			}
+			for zb0003 > 0 {
+				var za0003 string
+				var za0004 FieldCounts
+				zb0003--
+				za0003, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Fields")
+					return
+				}
+				var zb0004 uint32
+				zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Fields", za0003)
+					return
+				}
+				if zb0004 != uint32(fieldCount) {
+					err = msgp.ArrayError{Wanted: uint32(fieldCount), Got: zb0004}
+					return
+				}
+				for za0005 := range za0004 {
+					za0004[za0005], bts, err = msgp.ReadIntBytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Fields", za0003, za0005)
+						return
+					}
+				}
+				z.Fields[za0003] = za0004
+			}
+		case "Positions":
+			var zb0005 uint32
+			zb0005, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Positions")
+				return
+			}
+			if z.Positions == nil {
+				z.Positions = make(map[string][]int, zb0005)
+			} else if len(z.Positions) > 0 {
+				for key := range z.Positions {
+					delete(z.Positions, key)
+				}
+			}
+			for zb0005 > 0 {
+				var za0006 string
+				var za0007 []int
+				zb0005--
+				za0006, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Positions")
+					return
+				}
+				var zb0006 uint32
+				zb0006, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Positions", za0006)
+					return
+				}
+				if cap(za0007) >= int(zb0006) {
+					za0007 = (za0007)[:zb0006]
+				} else {
+					za0007 = make([]int, zb0006)
+				}
+				for za0008 := range za0007 {
+					za0007[za0008], bts, err = msgp.ReadIntBytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Positions", za0006, za0008)
+						return
+					}
+				}
+				z.Positions[za0006] = za0007
+			}
+		case "Fingerprint":
+			var zb0007 uint32
+			zb0007, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Fingerprint")
+				return
+			}
+			for zb0007 > 0 {
+				zb0007--
+				field, bts, err = msgp.ReadMapKeyZC(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Fingerprint")
+					return
+				}
+				switch msgp.UnsafeString(field) {
+				case "ModTime":
+					z.Fingerprint.ModTime, bts, err = msgp.ReadInt64Bytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint", "ModTime")
+						return
+					}
+				case "Size":
+					z.Fingerprint.Size, bts, err = msgp.ReadInt64Bytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint", "Size")
+						return
+					}
+				case "Hash":
+					z.Fingerprint.Hash, bts, err = msgp.ReadUint64Bytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint", "Hash")
+						return
+					}
+				default:
+					bts, err = msgp.Skip(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprint")
+						return
+					}
+				}
+			}
+		case "Symbols":
+			var zb0008 uint32
+			zb0008, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Symbols")
+				return
+			}
+			if cap(z.Symbols) >= int(zb0008) {
+				z.Symbols = (z.Symbols)[:zb0008]
+			} else {
+				z.Symbols = make([]Symbol, zb0008)
+			}
+			for za0009 := range z.Symbols {
+				bts, err = z.Symbols[za0009].UnmarshalMsg(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Symbols", za0009)
+					return
+				}
+			}
+		case "Language":
+			z.Language, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Language")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -466,22 +959,324 @@ func (z *Document) Msgsize() (s int) {
 			s += msgp.StringPrefixSize + len(za0001) + msgp.IntSize
 		}
 	}
+	s += 7 + msgp.MapHeaderSize
+	if z.Fields != nil {
+		for za0003, za0004 := range z.Fields {
+			_ = za0004
+			s += msgp.StringPrefixSize + len(za0003) + msgp.ArrayHeaderSize + (fieldCount * (msgp.IntSize))
+		}
+	}
+	s += 10 + msgp.MapHeaderSize
+	if z.Positions != nil {
+		for za0006, za0007 := range z.Positions {
+			_ = za0007
+			s += msgp.StringPrefixSize + len(za0006) + msgp.ArrayHeaderSize + (len(za0007) * (msgp.IntSize))
+		}
+	}
+	s += 12 + 1 + 8 + msgp.Int64Size + 5 + msgp.Int64Size + 5 + msgp.Uint64Size + 8 + msgp.ArrayHeaderSize
+	for za0009 := range z.Symbols {
+		s += z.Symbols[za0009].Msgsize()
+	}
+	s += 9 + msgp.StringPrefixSize + len(z.Language)
 	return
 }
 
 // DecodeMsg implements msgp.Decodable
-func (z *Index) DecodeMsg(dc *msgp.Reader) (err error) {
-	var field []byte
-	_ = field
-	var zb0001 uint32
-	zb0001, err = dc.ReadMapHeader()
-	if err != nil {
-		err = msgp.WrapError(err)
-		return
-	}
-	for zb0001 > 0 {
-		zb0001--
-		field, err = dc.ReadMapKeyPtr()
+func (z *Field) DecodeMsg(dc *msgp.Reader) (err error) {
+	{
+		var zb0001 int
+		zb0001, err = dc.ReadInt()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		(*z) = Field(zb0001)
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z Field) EncodeMsg(en *msgp.Writer) (err error) {
+	err = en.WriteInt(int(z))
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z Field) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	o = msgp.AppendInt(o, int(z))
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Field) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	{
+		var zb0001 int
+		zb0001, bts, err = msgp.ReadIntBytes(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		(*z) = Field(zb0001)
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z Field) Msgsize() (s int) {
+	s = msgp.IntSize
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *FieldCounts) DecodeMsg(dc *msgp.Reader) (err error) {
+	var zb0001 uint32
+	zb0001, err = dc.ReadArrayHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	if zb0001 != uint32(fieldCount) {
+		err = msgp.ArrayError{Wanted: uint32(fieldCount), Got: zb0001}
+		return
+	}
+	for za0001 := range z {
+		z[za0001], err = dc.ReadInt()
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *FieldCounts) EncodeMsg(en *msgp.Writer) (err error) {
+	err = en.WriteArrayHeader(uint32(fieldCount))
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for za0001 := range z {
+		err = en.WriteInt(z[za0001])
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *FieldCounts) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	o = msgp.AppendArrayHeader(o, uint32(fieldCount))
+	for za0001 := range z {
+		o = msgp.AppendInt(o, z[za0001])
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *FieldCounts) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadArrayHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	if zb0001 != uint32(fieldCount) {
+		err = msgp.ArrayError{Wanted: uint32(fieldCount), Got: zb0001}
+		return
+	}
+	for za0001 := range z {
+		z[za0001], bts, err = msgp.ReadIntBytes(bts)
+		if err != nil {
+			err = msgp.WrapError(err, za0001)
+			return
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *FieldCounts) Msgsize() (s int) {
+	s = msgp.ArrayHeaderSize + (fieldCount * (msgp.IntSize))
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *Fingerprint) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "ModTime":
+			z.ModTime, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "ModTime")
+				return
+			}
+		case "Size":
+			z.Size, err = dc.ReadInt64()
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "Hash":
+			z.Hash, err = dc.ReadUint64()
+			if err != nil {
+				err = msgp.WrapError(err, "Hash")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z Fingerprint) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 3
+	// write "ModTime"
+	err = en.Append(0x83, 0xa7, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.ModTime)
+	if err != nil {
+		err = msgp.WrapError(err, "ModTime")
+		return
+	}
+	// write "Size"
+	err = en.Append(0xa4, 0x53, 0x69, 0x7a, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt64(z.Size)
+	if err != nil {
+		err = msgp.WrapError(err, "Size")
+		return
+	}
+	// write "Hash"
+	err = en.Append(0xa4, 0x48, 0x61, 0x73, 0x68)
+	if err != nil {
+		return
+	}
+	err = en.WriteUint64(z.Hash)
+	if err != nil {
+		err = msgp.WrapError(err, "Hash")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z Fingerprint) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 3
+	// string "ModTime"
+	o = append(o, 0x83, 0xa7, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65)
+	o = msgp.AppendInt64(o, z.ModTime)
+	// string "Size"
+	o = append(o, 0xa4, 0x53, 0x69, 0x7a, 0x65)
+	o = msgp.AppendInt64(o, z.Size)
+	// string "Hash"
+	o = append(o, 0xa4, 0x48, 0x61, 0x73, 0x68)
+	o = msgp.AppendUint64(o, z.Hash)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Fingerprint) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "ModTime":
+			z.ModTime, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "ModTime")
+				return
+			}
+		case "Size":
+			z.Size, bts, err = msgp.ReadInt64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Size")
+				return
+			}
+		case "Hash":
+			z.Hash, bts, err = msgp.ReadUint64Bytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Hash")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z Fingerprint) Msgsize() (s int) {
+	s = 1 + 8 + msgp.Int64Size + 5 + msgp.Int64Size + 5 + msgp.Uint64Size
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *Index) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
 		if err != nil {
 			err = msgp.WrapError(err)
 			return
@@ -491,156 +1286,987 @@ func (z *Index) DecodeMsg(dc *msgp.Reader) (err error) {
 			var zb0002 uint32
 			zb0002, err = dc.ReadMapHeader()
 			if err != nil {
-				err = msgp.WrapError(err, "Words")
+				err = msgp.WrapError(err, "Words")
+				return
+			}
+			if z.Words == nil {
+				z.Words = make(map[string]*WordColumn, zb0002)
+			} else if len(z.Words) > 0 {
+				for key := range z.Words {
+					delete(z.Words, key)
+				}
+			}
+			for zb0002 > 0 {
+				zb0002--
+				var za0001 string
+				var za0002 *WordColumn
+				za0001, err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Words")
+					return
+				}
+				if dc.IsNil() {
+					err = dc.ReadNil()
+					if err != nil {
+						err = msgp.WrapError(err, "Words", za0001)
+						return
+					}
+					za0002 = nil
+				} else {
+					if za0002 == nil {
+						za0002 = new(WordColumn)
+					}
+					var zb0003 uint32
+					zb0003, err = dc.ReadMapHeader()
+					if err != nil {
+						err = msgp.WrapError(err, "Words", za0001)
+						return
+					}
+					for zb0003 > 0 {
+						zb0003--
+						field, err = dc.ReadMapKeyPtr()
+						if err != nil {
+							err = msgp.WrapError(err, "Words", za0001)
+							return
+						}
+						switch msgp.UnsafeString(field) {
+						case "Name":
+							za0002.Name, err = dc.ReadString()
+							if err != nil {
+								err = msgp.WrapError(err, "Words", za0001, "Name")
+								return
+							}
+						case "Docs":
+							var zb0004 uint32
+							zb0004, err = dc.ReadArrayHeader()
+							if err != nil {
+								err = msgp.WrapError(err, "Words", za0001, "Docs")
+								return
+							}
+							if cap(za0002.Docs) >= int(zb0004) {
+								za0002.Docs = (za0002.Docs)[:zb0004]
+							} else {
+								za0002.Docs = make([]Posting, zb0004)
+							}
+							for za0003 := range za0002.Docs {
+								err = za0002.Docs[za0003].DecodeMsg(dc)
+								if err != nil {
+									err = msgp.WrapError(err, "Words", za0001, "Docs", za0003)
+									return
+								}
+							}
+						default:
+							err = dc.Skip()
+							if err != nil {
+								err = msgp.WrapError(err, "Words", za0001)
+								return
+							}
+						}
+					}
+				}
+				z.Words[za0001] = za0002
+			}
+		case "Names":
+			var zb0005 uint32
+			zb0005, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Names")
+				return
+			}
+			if cap(z.Names) >= int(zb0005) {
+				z.Names = (z.Names)[:zb0005]
+			} else {
+				z.Names = make([]string, zb0005)
+			}
+			for za0004 := range z.Names {
+				z.Names[za0004], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Names", za0004)
+					return
+				}
+			}
+		case "Fingerprints":
+			var zb0006 uint32
+			zb0006, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Fingerprints")
+				return
+			}
+			if cap(z.Fingerprints) >= int(zb0006) {
+				z.Fingerprints = (z.Fingerprints)[:zb0006]
+			} else {
+				z.Fingerprints = make([]Fingerprint, zb0006)
+			}
+			for za0005 := range z.Fingerprints {
+				var zb0007 uint32
+				zb0007, err = dc.ReadMapHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Fingerprints", za0005)
+					return
+				}
+				for zb0007 > 0 {
+					zb0007--
+					field, err = dc.ReadMapKeyPtr()
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprints", za0005)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "ModTime":
+						z.Fingerprints[za0005].ModTime, err = dc.ReadInt64()
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005, "ModTime")
+							return
+						}
+					case "Size":
+						z.Fingerprints[za0005].Size, err = dc.ReadInt64()
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005, "Size")
+							return
+						}
+					case "Hash":
+						z.Fingerprints[za0005].Hash, err = dc.ReadUint64()
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005, "Hash")
+							return
+						}
+					default:
+						err = dc.Skip()
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005)
+							return
+						}
+					}
+				}
+			}
+		case "Lengths":
+			var zb0008 uint32
+			zb0008, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Lengths")
+				return
+			}
+			if cap(z.Lengths) >= int(zb0008) {
+				z.Lengths = (z.Lengths)[:zb0008]
+			} else {
+				z.Lengths = make([]int, zb0008)
+			}
+			for za0006 := range z.Lengths {
+				z.Lengths[za0006], err = dc.ReadInt()
+				if err != nil {
+					err = msgp.WrapError(err, "Lengths", za0006)
+					return
+				}
+			}
+		case "Free":
+			var zb0009 uint32
+			zb0009, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Free")
+				return
+			}
+			if cap(z.Free) >= int(zb0009) {
+				z.Free = (z.Free)[:zb0009]
+			} else {
+				z.Free = make([]int, zb0009)
+			}
+			for za0007 := range z.Free {
+				z.Free[za0007], err = dc.ReadInt()
+				if err != nil {
+					err = msgp.WrapError(err, "Free", za0007)
+					return
+				}
+			}
+		case "Symbols":
+			var zb0010 uint32
+			zb0010, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Symbols")
+				return
+			}
+			if cap(z.Symbols) >= int(zb0010) {
+				z.Symbols = (z.Symbols)[:zb0010]
+			} else {
+				z.Symbols = make([][]Symbol, zb0010)
+			}
+			for za0008 := range z.Symbols {
+				var zb0011 uint32
+				zb0011, err = dc.ReadArrayHeader()
+				if err != nil {
+					err = msgp.WrapError(err, "Symbols", za0008)
+					return
+				}
+				if cap(z.Symbols[za0008]) >= int(zb0011) {
+					z.Symbols[za0008] = (z.Symbols[za0008])[:zb0011]
+				} else {
+					z.Symbols[za0008] = make([]Symbol, zb0011)
+				}
+				for za0009 := range z.Symbols[za0008] {
+					err = z.Symbols[za0008][za0009].DecodeMsg(dc)
+					if err != nil {
+						err = msgp.WrapError(err, "Symbols", za0008, za0009)
+						return
+					}
+				}
+			}
+		case "Languages":
+			var zb0012 uint32
+			zb0012, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Languages")
+				return
+			}
+			if cap(z.Languages) >= int(zb0012) {
+				z.Languages = (z.Languages)[:zb0012]
+			} else {
+				z.Languages = make([]string, zb0012)
+			}
+			for za0010 := range z.Languages {
+				z.Languages[za0010], err = dc.ReadString()
+				if err != nil {
+					err = msgp.WrapError(err, "Languages", za0010)
+					return
+				}
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Index) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 7
+	// write "Words"
+	err = en.Append(0x87, 0xa5, 0x57, 0x6f, 0x72, 0x64, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.Words)))
+	if err != nil {
+		err = msgp.WrapError(err, "Words")
+		return
+	}
+	for za0001, za0002 := range z.Words {
+		err = en.WriteString(za0001)
+		if err != nil {
+			err = msgp.WrapError(err, "Words")
+			return
+		}
+		if za0002 == nil {
+			err = en.WriteNil()
+			if err != nil {
+				return
+			}
+		} else {
+			// map header, size 2
+			// write "Name"
+			err = en.Append(0x82, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+			if err != nil {
+				return
+			}
+			err = en.WriteString(za0002.Name)
+			if err != nil {
+				err = msgp.WrapError(err, "Words", za0001, "Name")
+				return
+			}
+			// write "Docs"
+			err = en.Append(0xa4, 0x44, 0x6f, 0x63, 0x73)
+			if err != nil {
+				return
+			}
+			err = en.WriteArrayHeader(uint32(len(za0002.Docs)))
+			if err != nil {
+				err = msgp.WrapError(err, "Words", za0001, "Docs")
+				return
+			}
+			for za0003 := range za0002.Docs {
+				err = za0002.Docs[za0003].EncodeMsg(en)
+				if err != nil {
+					err = msgp.WrapError(err, "Words", za0001, "Docs", za0003)
+					return
+				}
+			}
+		}
+	}
+	// write "Names"
+	err = en.Append(0xa5, 0x4e, 0x61, 0x6d, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Names)))
+	if err != nil {
+		err = msgp.WrapError(err, "Names")
+		return
+	}
+	for za0004 := range z.Names {
+		err = en.WriteString(z.Names[za0004])
+		if err != nil {
+			err = msgp.WrapError(err, "Names", za0004)
+			return
+		}
+	}
+	// write "Fingerprints"
+	err = en.Append(0xac, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Fingerprints)))
+	if err != nil {
+		err = msgp.WrapError(err, "Fingerprints")
+		return
+	}
+	for za0005 := range z.Fingerprints {
+		// map header, size 3
+		// write "ModTime"
+		err = en.Append(0x83, 0xa7, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt64(z.Fingerprints[za0005].ModTime)
+		if err != nil {
+			err = msgp.WrapError(err, "Fingerprints", za0005, "ModTime")
+			return
+		}
+		// write "Size"
+		err = en.Append(0xa4, 0x53, 0x69, 0x7a, 0x65)
+		if err != nil {
+			return
+		}
+		err = en.WriteInt64(z.Fingerprints[za0005].Size)
+		if err != nil {
+			err = msgp.WrapError(err, "Fingerprints", za0005, "Size")
+			return
+		}
+		// write "Hash"
+		err = en.Append(0xa4, 0x48, 0x61, 0x73, 0x68)
+		if err != nil {
+			return
+		}
+		err = en.WriteUint64(z.Fingerprints[za0005].Hash)
+		if err != nil {
+			err = msgp.WrapError(err, "Fingerprints", za0005, "Hash")
+			return
+		}
+	}
+	// write "Lengths"
+	err = en.Append(0xa7, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Lengths)))
+	if err != nil {
+		err = msgp.WrapError(err, "Lengths")
+		return
+	}
+	for za0006 := range z.Lengths {
+		err = en.WriteInt(z.Lengths[za0006])
+		if err != nil {
+			err = msgp.WrapError(err, "Lengths", za0006)
+			return
+		}
+	}
+	// write "Free"
+	err = en.Append(0xa4, 0x46, 0x72, 0x65, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Free)))
+	if err != nil {
+		err = msgp.WrapError(err, "Free")
+		return
+	}
+	for za0007 := range z.Free {
+		err = en.WriteInt(z.Free[za0007])
+		if err != nil {
+			err = msgp.WrapError(err, "Free", za0007)
+			return
+		}
+	}
+	// write "Symbols"
+	err = en.Append(0xa7, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Symbols)))
+	if err != nil {
+		err = msgp.WrapError(err, "Symbols")
+		return
+	}
+	for za0008 := range z.Symbols {
+		err = en.WriteArrayHeader(uint32(len(z.Symbols[za0008])))
+		if err != nil {
+			err = msgp.WrapError(err, "Symbols", za0008)
+			return
+		}
+		for za0009 := range z.Symbols[za0008] {
+			err = z.Symbols[za0008][za0009].EncodeMsg(en)
+			if err != nil {
+				err = msgp.WrapError(err, "Symbols", za0008, za0009)
+				return
+			}
+		}
+	}
+	// write "Languages"
+	err = en.Append(0xa9, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(len(z.Languages)))
+	if err != nil {
+		err = msgp.WrapError(err, "Languages")
+		return
+	}
+	for za0010 := range z.Languages {
+		err = en.WriteString(z.Languages[za0010])
+		if err != nil {
+			err = msgp.WrapError(err, "Languages", za0010)
+			return
+		}
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *Index) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 7
+	// string "Words"
+	o = append(o, 0x87, 0xa5, 0x57, 0x6f, 0x72, 0x64, 0x73)
+	o = msgp.AppendMapHeader(o, uint32(len(z.Words)))
+	for za0001, za0002 := range z.Words {
+		o = msgp.AppendString(o, za0001)
+		if za0002 == nil {
+			o = msgp.AppendNil(o)
+		} else {
+			// map header, size 2
+			// string "Name"
+			o = append(o, 0x82, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+			o = msgp.AppendString(o, za0002.Name)
+			// string "Docs"
+			o = append(o, 0xa4, 0x44, 0x6f, 0x63, 0x73)
+			o = msgp.AppendArrayHeader(o, uint32(len(za0002.Docs)))
+			for za0003 := range za0002.Docs {
+				o, err = za0002.Docs[za0003].MarshalMsg(o)
+				if err != nil {
+					err = msgp.WrapError(err, "Words", za0001, "Docs", za0003)
+					return
+				}
+			}
+		}
+	}
+	// string "Names"
+	o = append(o, 0xa5, 0x4e, 0x61, 0x6d, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Names)))
+	for za0004 := range z.Names {
+		o = msgp.AppendString(o, z.Names[za0004])
+	}
+	// string "Fingerprints"
+	o = append(o, 0xac, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Fingerprints)))
+	for za0005 := range z.Fingerprints {
+		// map header, size 3
+		// string "ModTime"
+		o = append(o, 0x83, 0xa7, 0x4d, 0x6f, 0x64, 0x54, 0x69, 0x6d, 0x65)
+		o = msgp.AppendInt64(o, z.Fingerprints[za0005].ModTime)
+		// string "Size"
+		o = append(o, 0xa4, 0x53, 0x69, 0x7a, 0x65)
+		o = msgp.AppendInt64(o, z.Fingerprints[za0005].Size)
+		// string "Hash"
+		o = append(o, 0xa4, 0x48, 0x61, 0x73, 0x68)
+		o = msgp.AppendUint64(o, z.Fingerprints[za0005].Hash)
+	}
+	// string "Lengths"
+	o = append(o, 0xa7, 0x4c, 0x65, 0x6e, 0x67, 0x74, 0x68, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Lengths)))
+	for za0006 := range z.Lengths {
+		o = msgp.AppendInt(o, z.Lengths[za0006])
+	}
+	// string "Free"
+	o = append(o, 0xa4, 0x46, 0x72, 0x65, 0x65)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Free)))
+	for za0007 := range z.Free {
+		o = msgp.AppendInt(o, z.Free[za0007])
+	}
+	// string "Symbols"
+	o = append(o, 0xa7, 0x53, 0x79, 0x6d, 0x62, 0x6f, 0x6c, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Symbols)))
+	for za0008 := range z.Symbols {
+		o = msgp.AppendArrayHeader(o, uint32(len(z.Symbols[za0008])))
+		for za0009 := range z.Symbols[za0008] {
+			o, err = z.Symbols[za0008][za0009].MarshalMsg(o)
+			if err != nil {
+				err = msgp.WrapError(err, "Symbols", za0008, za0009)
+				return
+			}
+		}
+	}
+	// string "Languages"
+	o = append(o, 0xa9, 0x4c, 0x61, 0x6e, 0x67, 0x75, 0x61, 0x67, 0x65, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(len(z.Languages)))
+	for za0010 := range z.Languages {
+		o = msgp.AppendString(o, z.Languages[za0010])
+	}
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Index) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Words":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadMapHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Words")
+				return
+			}
+			if z.Words == nil {
+				z.Words = make(map[string]*WordColumn, zb0002)
+			} else if len(z.Words) > 0 {
+				for key := range z.Words {
+					delete(z.Words, key)
+				}
+			}
+			for zb0002 > 0 {
+				var za0001 string
+				var za0002 *WordColumn
+				zb0002--
+				za0001, bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Words")
+					return
+				}
+				if msgp.IsNil(bts) {
+					bts, err = msgp.ReadNilBytes(bts)
+					if err != nil {
+						return
+					}
+					za0002 = nil
+				} else {
+					if za0002 == nil {
+						za0002 = new(WordColumn)
+					}
+					var zb0003 uint32
+					zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Words", za0001)
+						return
+					}
+					for zb0003 > 0 {
+						zb0003--
+						field, bts, err = msgp.ReadMapKeyZC(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Words", za0001)
+							return
+						}
+						switch msgp.UnsafeString(field) {
+						case "Name":
+							za0002.Name, bts, err = msgp.ReadStringBytes(bts)
+							if err != nil {
+								err = msgp.WrapError(err, "Words", za0001, "Name")
+								return
+							}
+						case "Docs":
+							var zb0004 uint32
+							zb0004, bts, err = msgp.ReadArrayHeaderBytes(bts)
+							if err != nil {
+								err = msgp.WrapError(err, "Words", za0001, "Docs")
+								return
+							}
+							if cap(za0002.Docs) >= int(zb0004) {
+								za0002.Docs = (za0002.Docs)[:zb0004]
+							} else {
+								za0002.Docs = make([]Posting, zb0004)
+							}
+							for za0003 := range za0002.Docs {
+								bts, err = za0002.Docs[za0003].UnmarshalMsg(bts)
+								if err != nil {
+									err = msgp.WrapError(err, "Words", za0001, "Docs", za0003)
+									return
+								}
+							}
+						default:
+							bts, err = msgp.Skip(bts)
+							if err != nil {
+								err = msgp.WrapError(err, "Words", za0001)
+								return
+							}
+						}
+					}
+				}
+				z.Words[za0001] = za0002
+			}
+		case "Names":
+			var zb0005 uint32
+			zb0005, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Names")
+				return
+			}
+			if cap(z.Names) >= int(zb0005) {
+				z.Names = (z.Names)[:zb0005]
+			} else {
+				z.Names = make([]string, zb0005)
+			}
+			for za0004 := range z.Names {
+				z.Names[za0004], bts, err = msgp.ReadStringBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Names", za0004)
+					return
+				}
+			}
+		case "Fingerprints":
+			var zb0006 uint32
+			zb0006, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Fingerprints")
+				return
+			}
+			if cap(z.Fingerprints) >= int(zb0006) {
+				z.Fingerprints = (z.Fingerprints)[:zb0006]
+			} else {
+				z.Fingerprints = make([]Fingerprint, zb0006)
+			}
+			for za0005 := range z.Fingerprints {
+				var zb0007 uint32
+				zb0007, bts, err = msgp.ReadMapHeaderBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Fingerprints", za0005)
+					return
+				}
+				for zb0007 > 0 {
+					zb0007--
+					field, bts, err = msgp.ReadMapKeyZC(bts)
+					if err != nil {
+						err = msgp.WrapError(err, "Fingerprints", za0005)
+						return
+					}
+					switch msgp.UnsafeString(field) {
+					case "ModTime":
+						z.Fingerprints[za0005].ModTime, bts, err = msgp.ReadInt64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005, "ModTime")
+							return
+						}
+					case "Size":
+						z.Fingerprints[za0005].Size, bts, err = msgp.ReadInt64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005, "Size")
+							return
+						}
+					case "Hash":
+						z.Fingerprints[za0005].Hash, bts, err = msgp.ReadUint64Bytes(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005, "Hash")
+							return
+						}
+					default:
+						bts, err = msgp.Skip(bts)
+						if err != nil {
+							err = msgp.WrapError(err, "Fingerprints", za0005)
+							return
+						}
+					}
+				}
+			}
+		case "Lengths":
+			var zb0008 uint32
+			zb0008, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Lengths")
+				return
+			}
+			if cap(z.Lengths) >= int(zb0008) {
+				z.Lengths = (z.Lengths)[:zb0008]
+			} else {
+				z.Lengths = make([]int, zb0008)
+			}
+			for za0006 := range z.Lengths {
+				z.Lengths[za0006], bts, err = msgp.ReadIntBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Lengths", za0006)
+					return
+				}
+			}
+		case "Free":
+			var zb0009 uint32
+			zb0009, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Free")
+				return
+			}
+			if cap(z.Free) >= int(zb0009) {
+				z.Free = (z.Free)[:zb0009]
+			} else {
+				z.Free = make([]int, zb0009)
+			}
+			for za0007 := range z.Free {
+				z.Free[za0007], bts, err = msgp.ReadIntBytes(bts)
+				if err != nil {
+					err = msgp.WrapError(err, "Free", za0007)
+					return
+				}
+			}
+		case "Symbols":
+			var zb0010 uint32
+			zb0010, bts, err = msgp.ReadArrayHeaderBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Symbols")
 				return
 			}
-			if z.Words == nil {
-				z.Words = make(map[string]*WordColumn, zb0002)
-			} else if len(z.Words) > 0 {
-				for key := range z.Words {
-					delete(z.Words, key)
-				}
+			if cap(z.Symbols) >= int(zb0010) {
+				z.Symbols = (z.Symbols)[:zb0010]
+			} else {
+				z.Symbols = make([][]Symbol, zb0010)
 			}
-			for zb0002 > 0 {
-				zb0002--
-				var za0001 string
-				var za0002 *WordColumn
-				za0001, err = dc.ReadString()
+			for za0008 := range z.Symbols {
+				var zb0011 uint32
+				zb0011, bts, err = msgp.ReadArrayHeaderBytes(bts)
 				if err != nil {
-					err = msgp.WrapError(err, "Words")
+					err = msgp.WrapError(err, "Symbols", za0008)
 					return
 				}
-				if dc.IsNil() {
-					err = dc.ReadNil()
-					if err != nil {
-						err = msgp.WrapError(err, "Words", za0001)
-						return
-					}
-					za0002 = nil
+				if cap(z.Symbols[za0008]) >= int(zb0011) {
+					z.Symbols[za0008] = (z.Symbols[za0008])[:zb0011]
 				} else {
-					if za0002 == nil {
-						za0002 = new(WordColumn)
-					}
-					err = za0002.DecodeMsg(dc)
+					z.Symbols[za0008] = make([]Symbol, zb0011)
+				}
+				for za0009 := range z.Symbols[za0008] {
+					bts, err = z.Symbols[za0008][za0009].UnmarshalMsg(bts)
 					if err != nil {
-						err = msgp.WrapError(err, "Words", za0001)
+						err = msgp.WrapError(err, "Symbols", za0008, za0009)
 						return
 					}
 				}
-				z.Words[za0001] = za0002
 			}
-		case "Names":
-			var zb0003 uint32
-			zb0003, err = dc.ReadArrayHeader()
+		case "Languages":
+			var zb0012 uint32
+			zb0012, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Names")
+				err = msgp.WrapError(err, "Languages")
 				return
 			}
-			if cap(z.Names) >= int(zb0003) {
-				z.Names = (z.Names)[:zb0003]
+			if cap(z.Languages) >= int(zb0012) {
+				z.Languages = (z.Languages)[:zb0012]
 			} else {
-				z.Names = make([]string, zb0003)
+				z.Languages = make([]string, zb0012)
 			}
-			for za0003 := range z.Names {
-				z.Names[za0003], err = dc.ReadString()
+			for za0010 := range z.Languages {
+				z.Languages[za0010], bts, err = msgp.ReadStringBytes(bts)
 				if err != nil {
-					err = msgp.WrapError(err, "Names", za0003)
+					err = msgp.WrapError(err, "Languages", za0010)
 					return
 				}
 			}
 		default:
-			err = dc.Skip()
+			bts, err = msgp.Skip(bts)
 			if err != nil {
 				err = msgp.WrapError(err)
 				return
 			}
 		}
 	}
+	o = bts
 	return
 }
 
-// EncodeMsg implements msgp.Encodable
-func (z *Index) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 2
-	// write "Words"
-	err = en.Append(0x82, 0xa5, 0x57, 0x6f, 0x72, 0x64, 0x73)
-	if err != nil {
-		return
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Index) Msgsize() (s int) {
+	s = 1 + 6 + msgp.MapHeaderSize
+	if z.Words != nil {
+		for za0001, za0002 := range z.Words {
+			_ = za0002
+			s += msgp.StringPrefixSize + len(za0001)
+			if za0002 == nil {
+				s += msgp.NilSize
+			} else {
+				s += 1 + 5 + msgp.StringPrefixSize + len(za0002.Name) + 5 + msgp.ArrayHeaderSize
+				for za0003 := range za0002.Docs {
+					s += za0002.Docs[za0003].Msgsize()
+				}
+			}
+		}
 	}
-	err = en.WriteMapHeader(uint32(len(z.Words)))
+	s += 6 + msgp.ArrayHeaderSize
+	for za0004 := range z.Names {
+		s += msgp.StringPrefixSize + len(z.Names[za0004])
+	}
+	s += 13 + msgp.ArrayHeaderSize + (len(z.Fingerprints) * (19 + msgp.Int64Size + msgp.Int64Size + msgp.Uint64Size)) + 8 + msgp.ArrayHeaderSize + (len(z.Lengths) * (msgp.IntSize)) + 5 + msgp.ArrayHeaderSize + (len(z.Free) * (msgp.IntSize)) + 8 + msgp.ArrayHeaderSize
+	for za0008 := range z.Symbols {
+		s += msgp.ArrayHeaderSize
+		for za0009 := range z.Symbols[za0008] {
+			s += z.Symbols[za0008][za0009].Msgsize()
+		}
+	}
+	s += 10 + msgp.ArrayHeaderSize
+	for za0010 := range z.Languages {
+		s += msgp.StringPrefixSize + len(z.Languages[za0010])
+	}
+	return
+}
+
+// DecodeMsg implements msgp.Decodable
+func (z *Posting) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
 	if err != nil {
-		err = msgp.WrapError(err, "Words")
+		err = msgp.WrapError(err)
 		return
 	}
-	for za0001, za0002 := range z.Words {
-		err = en.WriteString(za0001)
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
 		if err != nil {
-			err = msgp.WrapError(err, "Words")
+			err = msgp.WrapError(err)
 			return
 		}
-		if za0002 == nil {
-			err = en.WriteNil()
+		switch msgp.UnsafeString(field) {
+		case "Doc":
+			z.Doc, err = dc.ReadInt()
 			if err != nil {
+				err = msgp.WrapError(err, "Doc")
 				return
 			}
-		} else {
-			err = za0002.EncodeMsg(en)
+		case "Count":
+			z.Count, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Count")
+				return
+			}
+		case "Fields":
+			var zb0002 uint32
+			zb0002, err = dc.ReadArrayHeader()
+			if err != nil {
+				err = msgp.WrapError(err, "Fields")
+				return
+			}
+			if zb0002 != uint32(fieldCount) {
+				err = msgp.ArrayError{Wanted: uint32(fieldCount), Got: zb0002}
+				return
+			}
+			for za0001 := range z.Fields {
+				z.Fields[za0001], err = dc.ReadInt()
+				if err != nil {
+					err = msgp.WrapError(err, "Fields", za0001)
+					return
+				}
+			}
+		case "Positions":
+			z.Positions, err = dc.ReadBytes(z.Positions)
+			if err != nil {
+				err = msgp.WrapError(err, "Positions")
+				return
+			}
+		default:
+			err = dc.Skip()
 			if err != nil {
-				err = msgp.WrapError(err, "Words", za0001)
+				err = msgp.WrapError(err)
 				return
 			}
 		}
 	}
-	// write "Names"
-	err = en.Append(0xa5, 0x4e, 0x61, 0x6d, 0x65, 0x73)
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Posting) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "Doc"
+	err = en.Append(0x84, 0xa3, 0x44, 0x6f, 0x63)
 	if err != nil {
 		return
 	}
-	err = en.WriteArrayHeader(uint32(len(z.Names)))
+	err = en.WriteInt(z.Doc)
 	if err != nil {
-		err = msgp.WrapError(err, "Names")
+		err = msgp.WrapError(err, "Doc")
+		return
+	}
+	// write "Count"
+	err = en.Append(0xa5, 0x43, 0x6f, 0x75, 0x6e, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Count)
+	if err != nil {
+		err = msgp.WrapError(err, "Count")
+		return
+	}
+	// write "Fields"
+	err = en.Append(0xa6, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteArrayHeader(uint32(fieldCount))
+	if err != nil {
+		err = msgp.WrapError(err, "Fields")
 		return
 	}
-	for za0003 := range z.Names {
-		err = en.WriteString(z.Names[za0003])
+	for za0001 := range z.Fields {
+		err = en.WriteInt(z.Fields[za0001])
 		if err != nil {
-			err = msgp.WrapError(err, "Names", za0003)
+			err = msgp.WrapError(err, "Fields", za0001)
 			return
 		}
 	}
+	// write "Positions"
+	err = en.Append(0xa9, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	if err != nil {
+		return
+	}
+	err = en.WriteBytes(z.Positions)
+	if err != nil {
+		err = msgp.WrapError(err, "Positions")
+		return
+	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z *Index) MarshalMsg(b []byte) (o []byte, err error) {
+func (z *Posting) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 2
-	// string "Words"
-	o = append(o, 0x82, 0xa5, 0x57, 0x6f, 0x72, 0x64, 0x73)
-	o = msgp.AppendMapHeader(o, uint32(len(z.Words)))
-	for za0001, za0002 := range z.Words {
-		o = msgp.AppendString(o, za0001)
-		if za0002 == nil {
-			o = msgp.AppendNil(o)
-		} else {
-			o, err = za0002.MarshalMsg(o)
-			if err != nil {
-				err = msgp.WrapError(err, "Words", za0001)
-				return
-			}
-		}
-	}
-	// string "Names"
-	o = append(o, 0xa5, 0x4e, 0x61, 0x6d, 0x65, 0x73)
-	o = msgp.AppendArrayHeader(o, uint32(len(z.Names)))
-	for za0003 := range z.Names {
-		o = msgp.AppendString(o, z.Names[za0003])
-	}
+	// map header, size 4
+	// string "Doc"
+	o = append(o, 0x84, 0xa3, 0x44, 0x6f, 0x63)
+	o = msgp.AppendInt(o, z.Doc)
+	// string "Count"
+	o = append(o, 0xa5, 0x43, 0x6f, 0x75, 0x6e, 0x74)
+	o = msgp.AppendInt(o, z.Count)
+	// string "Fields"
+	o = append(o, 0xa6, 0x46, 0x69, 0x65, 0x6c, 0x64, 0x73)
+	o = msgp.AppendArrayHeader(o, uint32(fieldCount))
+	for za0001 := range z.Fields {
+		o = msgp.AppendInt(o, z.Fields[za0001])
+	}
+	// string "Positions"
+	o = append(o, 0xa9, 0x50, 0x6f, 0x73, 0x69, 0x74, 0x69, 0x6f, 0x6e, 0x73)
+	o = msgp.AppendBytes(o, z.Positions)
 	return
 }
 
 // UnmarshalMsg implements msgp.Unmarshaler
-func (z *Index) UnmarshalMsg(bts []byte) (o []byte, err error) {
+func (z *Posting) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	var field []byte
 	_ = field
 	var zb0001 uint32
@@ -657,66 +2283,42 @@ func (z *Index) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			return
 		}
 		switch msgp.UnsafeString(field) {
-		case "Words":
-			var zb0002 uint32
-			zb0002, bts, err = msgp.ReadMapHeaderBytes(bts)
+		case "Doc":
+			z.Doc, bts, err = msgp.ReadIntBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Words")
+				err = msgp.WrapError(err, "Doc")
 				return
 			}
-			if z.Words == nil {
-				z.Words = make(map[string]*WordColumn, zb0002)
-			} else if len(z.Words) > 0 {
-				for key := range z.Words {
-					delete(z.Words, key)
-				}
-			}
-			for zb0002 > 0 {
-				var za0001 string
-				var za0002 *WordColumn
-				zb0002--
-				za0001, bts, err = msgp.ReadStringBytes(bts)
-				if err != nil {
-					err = msgp.WrapError(err, "Words")
-					return
-				}
-				if msgp.IsNil(bts) {
-					bts, err = msgp.ReadNilBytes(bts)
-					if err != nil {
-						return
-					}
-					za0002 = nil
-				} else {
-					if za0002 == nil {
-						za0002 = new(WordColumn)
-					}
-					bts, err = za0002.UnmarshalMsg(bts)
-					if err != nil {
-						err = msgp.WrapError(err, "Words", za0001)
-						return
-					}
-				}
-				z.Words[za0001] = za0002
+		case "Count":
+			z.Count, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Count")
+				return
 			}
-		case "Names":
-			var zb0003 uint32
-			zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+		case "Fields":
+			var zb0002 uint32
+			zb0002, bts, err = msgp.ReadArrayHeaderBytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Names")
+				err = msgp.WrapError(err, "Fields")
 				return
 			}
-			if cap(z.Names) >= int(zb0003) {
-				z.Names = (z.Names)[:zb0003]
-			} else {
-				z.Names = make([]string, zb0003)
+			if zb0002 != uint32(fieldCount) {
+				err = msgp.ArrayError{Wanted: uint32(fieldCount), Got: zb0002}
+				return
 			}
-			for za0003 := range z.Names {
-				z.Names[za0003], bts, err = msgp.ReadStringBytes(bts)
+			for za0001 := range z.Fields {
+				z.Fields[za0001], bts, err = msgp.ReadIntBytes(bts)
 				if err != nil {
-					err = msgp.WrapError(err, "Names", za0003)
+					err = msgp.WrapError(err, "Fields", za0001)
 					return
 				}
 			}
+		case "Positions":
+			z.Positions, bts, err = msgp.ReadBytesBytes(bts, z.Positions)
+			if err != nil {
+				err = msgp.WrapError(err, "Positions")
+				return
+			}
 		default:
 			bts, err = msgp.Skip(bts)
 			if err != nil {
@@ -730,23 +2332,8 @@ func (z *Index) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z *Index) Msgsize() (s int) {
-	s = 1 + 6 + msgp.MapHeaderSize
-	if z.Words != nil {
-		for za0001, za0002 := range z.Words {
-			_ = za0002
-			s += msgp.StringPrefixSize + len(za0001)
-			if za0002 == nil {
-				s += msgp.NilSize
-			} else {
-				s += za0002.Msgsize()
-			}
-		}
-	}
-	s += 6 + msgp.ArrayHeaderSize
-	for za0003 := range z.Names {
-		s += msgp.StringPrefixSize + len(z.Names[za0003])
-	}
+func (z *Posting) Msgsize() (s int) {
+	s = 1 + 4 + msgp.IntSize + 6 + msgp.IntSize + 7 + msgp.ArrayHeaderSize + (fieldCount * (msgp.IntSize)) + 10 + msgp.BytesPrefixSize + len(z.Positions)
 	return
 }
 
@@ -774,16 +2361,22 @@ func (z *Score) DecodeMsg(dc *msgp.Reader) (err error) {
 				err = msgp.WrapError(err, "Document")
 				return
 			}
-		case "Rank":
-			z.Rank, err = dc.ReadInt()
+		case "Score":
+			z.Score, err = dc.ReadFloat64()
 			if err != nil {
-				err = msgp.WrapError(err, "Rank")
+				err = msgp.WrapError(err, "Score")
+				return
+			}
+		case "Distance":
+			z.Distance, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Distance")
 				return
 			}
-		case "NameDistance":
-			z.NameDistance, err = dc.ReadInt()
+		case "NameSimilarity":
+			z.NameSimilarity, err = dc.ReadFloat64()
 			if err != nil {
-				err = msgp.WrapError(err, "NameDistance")
+				err = msgp.WrapError(err, "NameSimilarity")
 				return
 			}
 		default:
@@ -798,10 +2391,10 @@ func (z *Score) DecodeMsg(dc *msgp.Reader) (err error) {
 }
 
 // EncodeMsg implements msgp.Encodable
-func (z Score) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 3
+func (z *Score) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
 	// write "Document"
-	err = en.Append(0x83, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
+	err = en.Append(0x84, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
 	if err != nil {
 		return
 	}
@@ -810,42 +2403,55 @@ func (z Score) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err, "Document")
 		return
 	}
-	// write "Rank"
-	err = en.Append(0xa4, 0x52, 0x61, 0x6e, 0x6b)
+	// write "Score"
+	err = en.Append(0xa5, 0x53, 0x63, 0x6f, 0x72, 0x65)
 	if err != nil {
 		return
 	}
-	err = en.WriteInt(z.Rank)
+	err = en.WriteFloat64(z.Score)
 	if err != nil {
-		err = msgp.WrapError(err, "Rank")
+		err = msgp.WrapError(err, "Score")
+		return
+	}
+	// write "Distance"
+	err = en.Append(0xa8, 0x44, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Distance)
+	if err != nil {
+		err = msgp.WrapError(err, "Distance")
 		return
 	}
-	// write "NameDistance"
-	err = en.Append(0xac, 0x4e, 0x61, 0x6d, 0x65, 0x44, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65)
+	// write "NameSimilarity"
+	err = en.Append(0xae, 0x4e, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79)
 	if err != nil {
 		return
 	}
-	err = en.WriteInt(z.NameDistance)
+	err = en.WriteFloat64(z.NameSimilarity)
 	if err != nil {
-		err = msgp.WrapError(err, "NameDistance")
+		err = msgp.WrapError(err, "NameSimilarity")
 		return
 	}
 	return
 }
 
 // MarshalMsg implements msgp.Marshaler
-func (z Score) MarshalMsg(b []byte) (o []byte, err error) {
+func (z *Score) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
-	// map header, size 3
+	// map header, size 4
 	// string "Document"
-	o = append(o, 0x83, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
-	o = msgp.AppendString(o, z.Document)
-	// string "Rank"
-	o = append(o, 0xa4, 0x52, 0x61, 0x6e, 0x6b)
-	o = msgp.AppendInt(o, z.Rank)
-	// string "NameDistance"
-	o = append(o, 0xac, 0x4e, 0x61, 0x6d, 0x65, 0x44, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65)
-	o = msgp.AppendInt(o, z.NameDistance)
+	o = append(o, 0x84, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
+	o = msgp.AppendString(o, z.Document)
+	// string "Score"
+	o = append(o, 0xa5, 0x53, 0x63, 0x6f, 0x72, 0x65)
+	o = msgp.AppendFloat64(o, z.Score)
+	// string "Distance"
+	o = append(o, 0xa8, 0x44, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65)
+	o = msgp.AppendInt(o, z.Distance)
+	// string "NameSimilarity"
+	o = append(o, 0xae, 0x4e, 0x61, 0x6d, 0x65, 0x53, 0x69, 0x6d, 0x69, 0x6c, 0x61, 0x72, 0x69, 0x74, 0x79)
+	o = msgp.AppendFloat64(o, z.NameSimilarity)
 	return
 }
 
@@ -873,16 +2479,22 @@ func (z *Score) UnmarshalMsg(bts []byte) (o []byte, err error) {
 				err = msgp.WrapError(err, "Document")
 				return
 			}
-		case "Rank":
-			z.Rank, bts, err = msgp.ReadIntBytes(bts)
+		case "Score":
+			z.Score, bts, err = msgp.ReadFloat64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "Rank")
+				err = msgp.WrapError(err, "Score")
+				return
+			}
+		case "Distance":
+			z.Distance, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Distance")
 				return
 			}
-		case "NameDistance":
-			z.NameDistance, bts, err = msgp.ReadIntBytes(bts)
+		case "NameSimilarity":
+			z.NameSimilarity, bts, err = msgp.ReadFloat64Bytes(bts)
 			if err != nil {
-				err = msgp.WrapError(err, "NameDistance")
+				err = msgp.WrapError(err, "NameSimilarity")
 				return
 			}
 		default:
@@ -898,8 +2510,8 @@ func (z *Score) UnmarshalMsg(bts []byte) (o []byte, err error) {
 }
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
-func (z Score) Msgsize() (s int) {
-	s = 1 + 9 + msgp.StringPrefixSize + len(z.Document) + 5 + msgp.IntSize + 13 + msgp.IntSize
+func (z *Score) Msgsize() (s int) {
+	s = 1 + 9 + msgp.StringPrefixSize + len(z.Document) + 6 + msgp.Float64Size + 9 + msgp.IntSize + 15 + msgp.Float64Size
 	return
 }
 
@@ -917,48 +2529,11 @@ func (z *ScoreList) DecodeMsg(dc *msgp.Reader) (err error) {
 		(*z) = make(ScoreList, zb0002)
 	}
 	for zb0001 := range *z {
-		var field []byte
-		_ = field
-		var zb0003 uint32
-		zb0003, err = dc.ReadMapHeader()
+		err = (*z)[zb0001].DecodeMsg(dc)
 		if err != nil {
 			err = msgp.WrapError(err, zb0001)
 			return
 		}
-		for zb0003 > 0 {
-			zb0003--
-			field, err = dc.ReadMapKeyPtr()
-			if err != nil {
-				err = msgp.WrapError(err, zb0001)
-				return
-			}
-			switch msgp.UnsafeString(field) {
-			case "Document":
-				(*z)[zb0001].Document, err = dc.ReadString()
-				if err != nil {
-					err = msgp.WrapError(err, zb0001, "Document")
-					return
-				}
-			case "Rank":
-				(*z)[zb0001].Rank, err = dc.ReadInt()
-				if err != nil {
-					err = msgp.WrapError(err, zb0001, "Rank")
-					return
-				}
-			case "NameDistance":
-				(*z)[zb0001].NameDistance, err = dc.ReadInt()
-				if err != nil {
-					err = msgp.WrapError(err, zb0001, "NameDistance")
-					return
-				}
-			default:
-				err = dc.Skip()
-				if err != nil {
-					err = msgp.WrapError(err, zb0001)
-					return
-				}
-			}
-		}
 	}
 	return
 }
@@ -970,36 +2545,10 @@ func (z ScoreList) EncodeMsg(en *msgp.Writer) (err error) {
 		err = msgp.WrapError(err)
 		return
 	}
-	for zb0004 := range z {
-		// map header, size 3
-		// write "Document"
-		err = en.Append(0x83, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
-		if err != nil {
-			return
-		}
-		err = en.WriteString(z[zb0004].Document)
-		if err != nil {
-			err = msgp.WrapError(err, zb0004, "Document")
-			return
-		}
-		// write "Rank"
-		err = en.Append(0xa4, 0x52, 0x61, 0x6e, 0x6b)
-		if err != nil {
-			return
-		}
-		err = en.WriteInt(z[zb0004].Rank)
-		if err != nil {
-			err = msgp.WrapError(err, zb0004, "Rank")
-			return
-		}
-		// write "NameDistance"
-		err = en.Append(0xac, 0x4e, 0x61, 0x6d, 0x65, 0x44, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65)
-		if err != nil {
-			return
-		}
-		err = en.WriteInt(z[zb0004].NameDistance)
+	for zb0003 := range z {
+		err = z[zb0003].EncodeMsg(en)
 		if err != nil {
-			err = msgp.WrapError(err, zb0004, "NameDistance")
+			err = msgp.WrapError(err, zb0003)
 			return
 		}
 	}
@@ -1010,17 +2559,12 @@ func (z ScoreList) EncodeMsg(en *msgp.Writer) (err error) {
 func (z ScoreList) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.Require(b, z.Msgsize())
 	o = msgp.AppendArrayHeader(o, uint32(len(z)))
-	for zb0004 := range z {
-		// map header, size 3
-		// string "Document"
-		o = append(o, 0x83, 0xa8, 0x44, 0x6f, 0x63, 0x75, 0x6d, 0x65, 0x6e, 0x74)
-		o = msgp.AppendString(o, z[zb0004].Document)
-		// string "Rank"
-		o = append(o, 0xa4, 0x52, 0x61, 0x6e, 0x6b)
-		o = msgp.AppendInt(o, z[zb0004].Rank)
-		// string "NameDistance"
-		o = append(o, 0xac, 0x4e, 0x61, 0x6d, 0x65, 0x44, 0x69, 0x73, 0x74, 0x61, 0x6e, 0x63, 0x65)
-		o = msgp.AppendInt(o, z[zb0004].NameDistance)
+	for zb0003 := range z {
+		o, err = z[zb0003].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, zb0003)
+			return
+		}
 	}
 	return
 }
@@ -1039,48 +2583,11 @@ func (z *ScoreList) UnmarshalMsg(bts []byte) (o []byte, err error) {
 		(*z) = make(ScoreList, zb0002)
 	}
 	for zb0001 := range *z {
-		var field []byte
-		_ = field
-		var zb0003 uint32
-		zb0003, bts, err = msgp.ReadMapHeaderBytes(bts)
+		bts, err = (*z)[zb0001].UnmarshalMsg(bts)
 		if err != nil {
 			err = msgp.WrapError(err, zb0001)
 			return
 		}
-		for zb0003 > 0 {
-			zb0003--
-			field, bts, err = msgp.ReadMapKeyZC(bts)
-			if err != nil {
-				err = msgp.WrapError(err, zb0001)
-				return
-			}
-			switch msgp.UnsafeString(field) {
-			case "Document":
-				(*z)[zb0001].Document, bts, err = msgp.ReadStringBytes(bts)
-				if err != nil {
-					err = msgp.WrapError(err, zb0001, "Document")
-					return
-				}
-			case "Rank":
-				(*z)[zb0001].Rank, bts, err = msgp.ReadIntBytes(bts)
-				if err != nil {
-					err = msgp.WrapError(err, zb0001, "Rank")
-					return
-				}
-			case "NameDistance":
-				(*z)[zb0001].NameDistance, bts, err = msgp.ReadIntBytes(bts)
-				if err != nil {
-					err = msgp.WrapError(err, zb0001, "NameDistance")
-					return
-				}
-			default:
-				bts, err = msgp.Skip(bts)
-				if err != nil {
-					err = msgp.WrapError(err, zb0001)
-					return
-				}
-			}
-		}
 	}
 	o = bts
 	return
@@ -1089,8 +2596,8 @@ func (z *ScoreList) UnmarshalMsg(bts []byte) (o []byte, err error) {
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z ScoreList) Msgsize() (s int) {
 	s = msgp.ArrayHeaderSize
-	for zb0004 := range z {
-		s += 1 + 9 + msgp.StringPrefixSize + len(z[zb0004].Document) + 5 + msgp.IntSize + 13 + msgp.IntSize
+	for zb0003 := range z {
+		s += z[zb0003].Msgsize()
 	}
 	return
 }
@@ -1113,13 +2620,13 @@ func (z *Scores) DecodeMsg(dc *msgp.Reader) (err error) {
 	for zb0003 > 0 {
 		zb0003--
 		var zb0001 string
-		var zb0002 int
+		var zb0002 float64
 		zb0001, err = dc.ReadString()
 		if err != nil {
 			err = msgp.WrapError(err)
 			return
 		}
-		zb0002, err = dc.ReadInt()
+		zb0002, err = dc.ReadFloat64()
 		if err != nil {
 			err = msgp.WrapError(err, zb0001)
 			return
@@ -1142,7 +2649,7 @@ func (z Scores) EncodeMsg(en *msgp.Writer) (err error) {
 			err = msgp.WrapError(err)
 			return
 		}
-		err = en.WriteInt(zb0005)
+		err = en.WriteFloat64(zb0005)
 		if err != nil {
 			err = msgp.WrapError(err, zb0004)
 			return
@@ -1157,7 +2664,7 @@ func (z Scores) MarshalMsg(b []byte) (o []byte, err error) {
 	o = msgp.AppendMapHeader(o, uint32(len(z)))
 	for zb0004, zb0005 := range z {
 		o = msgp.AppendString(o, zb0004)
-		o = msgp.AppendInt(o, zb0005)
+		o = msgp.AppendFloat64(o, zb0005)
 	}
 	return
 }
@@ -1179,14 +2686,14 @@ func (z *Scores) UnmarshalMsg(bts []byte) (o []byte, err error) {
 	}
 	for zb0003 > 0 {
 		var zb0001 string
-		var zb0002 int
+		var zb0002 float64
 		zb0003--
 		zb0001, bts, err = msgp.ReadStringBytes(bts)
 		if err != nil {
 			err = msgp.WrapError(err)
 			return
 		}
-		zb0002, bts, err = msgp.ReadIntBytes(bts)
+		zb0002, bts, err = msgp.ReadFloat64Bytes(bts)
 		if err != nil {
 			err = msgp.WrapError(err, zb0001)
 			return
@@ -1203,7 +2710,7 @@ func (z Scores) Msgsize() (s int) {
 	if z != nil {
 		for zb0004, zb0005 := range z {
 			_ = zb0005
-			s += msgp.StringPrefixSize + len(zb0004) + msgp.IntSize
+			s += msgp.StringPrefixSize + len(zb0004) + msgp.Float64Size
 		}
 	}
 	return
@@ -1323,6 +2830,184 @@ func (z StrSet) Msgsize() (s int) {
 	return
 }
 
+// DecodeMsg implements msgp.Decodable
+func (z *Symbol) DecodeMsg(dc *msgp.Reader) (err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, err = dc.ReadMapHeader()
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, err = dc.ReadMapKeyPtr()
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			z.Name, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Kind":
+			z.Kind, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Kind")
+				return
+			}
+		case "Receiver":
+			z.Receiver, err = dc.ReadString()
+			if err != nil {
+				err = msgp.WrapError(err, "Receiver")
+				return
+			}
+		case "Line":
+			z.Line, err = dc.ReadInt()
+			if err != nil {
+				err = msgp.WrapError(err, "Line")
+				return
+			}
+		default:
+			err = dc.Skip()
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	return
+}
+
+// EncodeMsg implements msgp.Encodable
+func (z *Symbol) EncodeMsg(en *msgp.Writer) (err error) {
+	// map header, size 4
+	// write "Name"
+	err = en.Append(0x84, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Name)
+	if err != nil {
+		err = msgp.WrapError(err, "Name")
+		return
+	}
+	// write "Kind"
+	err = en.Append(0xa4, 0x4b, 0x69, 0x6e, 0x64)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Kind)
+	if err != nil {
+		err = msgp.WrapError(err, "Kind")
+		return
+	}
+	// write "Receiver"
+	err = en.Append(0xa8, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72)
+	if err != nil {
+		return
+	}
+	err = en.WriteString(z.Receiver)
+	if err != nil {
+		err = msgp.WrapError(err, "Receiver")
+		return
+	}
+	// write "Line"
+	err = en.Append(0xa4, 0x4c, 0x69, 0x6e, 0x65)
+	if err != nil {
+		return
+	}
+	err = en.WriteInt(z.Line)
+	if err != nil {
+		err = msgp.WrapError(err, "Line")
+		return
+	}
+	return
+}
+
+// MarshalMsg implements msgp.Marshaler
+func (z *Symbol) MarshalMsg(b []byte) (o []byte, err error) {
+	o = msgp.Require(b, z.Msgsize())
+	// map header, size 4
+	// string "Name"
+	o = append(o, 0x84, 0xa4, 0x4e, 0x61, 0x6d, 0x65)
+	o = msgp.AppendString(o, z.Name)
+	// string "Kind"
+	o = append(o, 0xa4, 0x4b, 0x69, 0x6e, 0x64)
+	o = msgp.AppendString(o, z.Kind)
+	// string "Receiver"
+	o = append(o, 0xa8, 0x52, 0x65, 0x63, 0x65, 0x69, 0x76, 0x65, 0x72)
+	o = msgp.AppendString(o, z.Receiver)
+	// string "Line"
+	o = append(o, 0xa4, 0x4c, 0x69, 0x6e, 0x65)
+	o = msgp.AppendInt(o, z.Line)
+	return
+}
+
+// UnmarshalMsg implements msgp.Unmarshaler
+func (z *Symbol) UnmarshalMsg(bts []byte) (o []byte, err error) {
+	var field []byte
+	_ = field
+	var zb0001 uint32
+	zb0001, bts, err = msgp.ReadMapHeaderBytes(bts)
+	if err != nil {
+		err = msgp.WrapError(err)
+		return
+	}
+	for zb0001 > 0 {
+		zb0001--
+		field, bts, err = msgp.ReadMapKeyZC(bts)
+		if err != nil {
+			err = msgp.WrapError(err)
+			return
+		}
+		switch msgp.UnsafeString(field) {
+		case "Name":
+			z.Name, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Name")
+				return
+			}
+		case "Kind":
+			z.Kind, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Kind")
+				return
+			}
+		case "Receiver":
+			z.Receiver, bts, err = msgp.ReadStringBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Receiver")
+				return
+			}
+		case "Line":
+			z.Line, bts, err = msgp.ReadIntBytes(bts)
+			if err != nil {
+				err = msgp.WrapError(err, "Line")
+				return
+			}
+		default:
+			bts, err = msgp.Skip(bts)
+			if err != nil {
+				err = msgp.WrapError(err)
+				return
+			}
+		}
+	}
+	o = bts
+	return
+}
+
+// Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
+func (z *Symbol) Msgsize() (s int) {
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 5 + msgp.StringPrefixSize + len(z.Kind) + 9 + msgp.StringPrefixSize + len(z.Receiver) + 5 + msgp.IntSize
+	return
+}
+
 // DecodeMsg implements msgp.Decodable
 func (z *WordColumn) DecodeMsg(dc *msgp.Reader) (err error) {
 	var field []byte
@@ -1357,26 +3042,14 @@ func (z *WordColumn) DecodeMsg(dc *msgp.Reader) (err error) {
 			if cap(z.Docs) >= int(zb0002) {
 				z.Docs = (z.Docs)[:zb0002]
 			} else {
-				z.Docs = make([][2]int, zb0002)
+				z.Docs = make([]Posting, zb0002)
 			}
 			for za0001 := range z.Docs {
-				var zb0003 uint32
-				zb0003, err = dc.ReadArrayHeader()
+				err = z.Docs[za0001].DecodeMsg(dc)
 				if err != nil {
 					err = msgp.WrapError(err, "Docs", za0001)
 					return
 				}
-				if zb0003 != uint32(2) {
-					err = msgp.ArrayError{Wanted: uint32(2), Got: zb0003}
-					return
-				}
-				for za0002 := range z.Docs[za0001] {
-					z.Docs[za0001][za0002], err = dc.ReadInt()
-					if err != nil {
-						err = msgp.WrapError(err, "Docs", za0001, za0002)
-						return
-					}
-				}
 			}
 		default:
 			err = dc.Skip()
@@ -1413,18 +3086,11 @@ func (z *WordColumn) EncodeMsg(en *msgp.Writer) (err error) {
 		return
 	}
 	for za0001 := range z.Docs {
-		err = en.WriteArrayHeader(uint32(2))
+		err = z.Docs[za0001].EncodeMsg(en)
 		if err != nil {
 			err = msgp.WrapError(err, "Docs", za0001)
 			return
 		}
-		for za0002 := range z.Docs[za0001] {
-			err = en.WriteInt(z.Docs[za0001][za0002])
-			if err != nil {
-				err = msgp.WrapError(err, "Docs", za0001, za0002)
-				return
-			}
-		}
 	}
 	return
 }
@@ -1440,9 +3106,10 @@ func (z *WordColumn) MarshalMsg(b []byte) (o []byte, err error) {
 	o = append(o, 0xa4, 0x44, 0x6f, 0x63, 0x73)
 	o = msgp.AppendArrayHeader(o, uint32(len(z.Docs)))
 	for za0001 := range z.Docs {
-		o = msgp.AppendArrayHeader(o, uint32(2))
-		for za0002 := range z.Docs[za0001] {
-			o = msgp.AppendInt(o, z.Docs[za0001][za0002])
+		o, err = z.Docs[za0001].MarshalMsg(o)
+		if err != nil {
+			err = msgp.WrapError(err, "Docs", za0001)
+			return
 		}
 	}
 	return
@@ -1482,26 +3149,14 @@ func (z *WordColumn) UnmarshalMsg(bts []byte) (o []byte, err error) {
 			if cap(z.Docs) >= int(zb0002) {
 				z.Docs = (z.Docs)[:zb0002]
 			} else {
-				z.Docs = make([][2]int, zb0002)
+				z.Docs = make([]Posting, zb0002)
 			}
 			for za0001 := range z.Docs {
-				var zb0003 uint32
-				zb0003, bts, err = msgp.ReadArrayHeaderBytes(bts)
+				bts, err = z.Docs[za0001].UnmarshalMsg(bts)
 				if err != nil {
 					err = msgp.WrapError(err, "Docs", za0001)
 					return
 				}
-				if zb0003 != uint32(2) {
-					err = msgp.ArrayError{Wanted: uint32(2), Got: zb0003}
-					return
-				}
-				for za0002 := range z.Docs[za0001] {
-					z.Docs[za0001][za0002], bts, err = msgp.ReadIntBytes(bts)
-					if err != nil {
-						err = msgp.WrapError(err, "Docs", za0001, za0002)
-						return
-					}
-				}
 			}
 		default:
 			bts, err = msgp.Skip(bts)
@@ -1517,7 +3172,10 @@ func (z *WordColumn) UnmarshalMsg(bts []byte) (o []byte, err error) {
 
 // Msgsize returns an upper bound estimate of the number of bytes occupied by the serialized message
 func (z *WordColumn) Msgsize() (s int) {
-	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 5 + msgp.ArrayHeaderSize + (len(z.Docs) * (2 * (msgp.IntSize)))
+	s = 1 + 5 + msgp.StringPrefixSize + len(z.Name) + 5 + msgp.ArrayHeaderSize
+	for za0001 := range z.Docs {
+		s += z.Docs[za0001].Msgsize()
+	}
 	return
 }
 
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
+// ignoreScore excludes the floating point relevance which is covered by the bm25 tests.
+var ignoreScore = cmpopts.IgnoreFields(DocRelevance{}, "Score")
+
 func Test_new_has_capacity_matching_document_size(t *testing.T) {
 	index := New(20)
 	if index.Capacity() != 20 {
@@ -46,9 +51,9 @@ func Test_searching_multiple_documents_returns_document_matching_term(t *testing
 	index.Update(doc)
 
 	docs, _ := index.Search("hello")
-	expected := DocList{{Document: "foo.md", Count: 1}}
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs))
+	expected := DocList{{Document: "foo.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
 	}
 }
 
@@ -60,9 +65,9 @@ func Test_fuzzy_search_multiple_documents_should_return_matching_documents_by_te
 	index.Update(doc)
 
 	docs, _ := index.Search("hell")
-	expected := DocList{{Document: "foo.md", Count: 1, Distance: 1}, {Document: "bar.md", Count: 1, Distance: 7}}
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%v", cmp.Diff(expected, docs))
+	expected := DocList{{Document: "foo.md", Count: 1, Distance: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%v", cmp.Diff(expected, docs, ignoreScore))
 	}
 }
 
@@ -74,9 +79,9 @@ func Test_searching_multiple_documents_should_return_documents_matching_common_t
 	index.Update(doc)
 
 	docs, _ := index.Search("world")
-	expected := DocList{{Document: "foo.md", Count: 1}, {Document: "bar.md", Count: 1}}
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%v", cmp.Diff(expected, docs))
+	expected := DocList{{Document: "foo.md", Count: 1, Rank: 1}, {Document: "bar.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%v", cmp.Diff(expected, docs, ignoreScore))
 	}
 }
 
@@ -87,9 +92,9 @@ func Test_index_deduplicate_documents_on_update(t *testing.T) {
 	index.Update(doc)
 
 	docs, _ := index.Search("hello")
-	expected := DocList{{Document: "foo.md", Count: 1}}
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs))
+	expected := DocList{{Document: "foo.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
 	}
 }
 
@@ -101,9 +106,9 @@ func Test_index_search_term_not_found_after_update_removes_it(t *testing.T) {
 	index.Update(doc)
 
 	docs, _ := index.Search("world")
-	expected := DocList{{Document: "baz.md", Count: 1, Distance: 8}}
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`world`) mismatch (-want +got)\n%v", cmp.Diff(expected, docs))
+	expected := DocList{}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`world`) mismatch (-want +got)\n%v", cmp.Diff(expected, docs, ignoreScore))
 	}
 }
 
@@ -116,16 +121,16 @@ func Test_update_removing_word_should_update_search_terms(t *testing.T) {
 	doc = bazMD("world")
 	index.Update(doc)
 
-	expected := DocList{{Document: "bar.md", Count: 1}}
+	expected := DocList{{Document: "bar.md", Count: 1, Rank: 1}}
 	docs, _ := index.Search("ciao")
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`ciao`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs))
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`ciao`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
 	}
 
-	expected = DocList{{Document: "baz.md", Count: 1}, {Document: "bar.md", Count: 1}}
+	expected = DocList{{Document: "baz.md", Count: 1, Rank: 1}, {Document: "bar.md", Count: 1, Rank: 1}}
 	docs, _ = index.Search("world")
-	if !cmp.Equal(docs, expected) {
-		t.Errorf("index.Search(`world`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs))
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`world`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
 	}
 }
 
@@ -154,3 +159,188 @@ func barMD() *Document {
 		WordCount: map[string]int{"ciao": 1, "world": 1},
 	}
 }
+
+func Test_remove_drops_document_from_search_results(t *testing.T) {
+	index := New(20)
+	index.Update(fooMD())
+	index.Update(barMD())
+
+	if !index.Remove("foo.md") {
+		t.Errorf("index.Remove(`foo.md`)=false, want true")
+	}
+	if index.Remove("foo.md") {
+		t.Errorf("index.Remove(`foo.md`)=true on second call, want false")
+	}
+
+	docs, _ := index.Search("world")
+	expected := DocList{{Document: "bar.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`world`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
+	}
+	if !cmp.Equal(index.Documents(), []string{"bar.md"}) {
+		t.Errorf("index.Documents()=%v, want [bar.md]", index.Documents())
+	}
+}
+
+func Test_update_records_document_fingerprint(t *testing.T) {
+	index := New(20)
+	doc := fooMD()
+	doc.Fingerprint = Fingerprint{ModTime: 1, Size: 2, Hash: 3}
+	index.Update(doc)
+
+	fp, ok := index.Fingerprint("foo.md")
+	if !ok || fp != doc.Fingerprint {
+		t.Errorf("index.Fingerprint(`foo.md`)=%v, %v, want %v, true", fp, ok, doc.Fingerprint)
+	}
+}
+
+func Test_remove_reuses_position_and_drops_empty_columns(t *testing.T) {
+	index := New(2)
+	index.Update(fooMD())
+	index.Update(barMD())
+	index.Remove("foo.md")
+
+	if index.WordCount() != 2 {
+		t.Errorf("index.WordCount()=%v, want 2", index.WordCount())
+	}
+	if index.Len() != 1 {
+		t.Errorf("index.Len()=%v, want 1", index.Len())
+	}
+
+	index.Update(bazMD("hello"))
+	if len(index.Names) != 2 {
+		t.Errorf("len(index.Names)=%v, want 2", len(index.Names))
+	}
+	docs, _ := index.Search("hello")
+	expected := DocList{{Document: "baz.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`hello`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
+	}
+}
+
+func Test_compact_renumbers_documents(t *testing.T) {
+	index := New(3)
+	index.Update(fooMD())
+	index.Update(barMD())
+	index.Update(bazMD("world"))
+	index.Remove("foo.md")
+	index.Compact()
+
+	if !cmp.Equal(index.Names, []string{"bar.md", "baz.md"}) {
+		t.Errorf("index.Names=%v, want [bar.md baz.md]", index.Names)
+	}
+	expected := []Posting{
+		{Doc: 1, Count: 1, Fields: FieldCounts{FieldBody: 1}},
+		{Doc: 0, Count: 1, Fields: FieldCounts{FieldBody: 1}},
+	}
+	if !cmp.Equal(index.Words["world"].Docs, expected) {
+		t.Errorf("world.Docs mismatch (-want +got)\n%s", cmp.Diff(expected, index.Words["world"].Docs))
+	}
+
+	index.Update(bazMD("ciao"))
+	docs, _ := index.Search("ciao")
+	expectedDocs := DocList{{Document: "baz.md", Count: 1, Rank: 1}, {Document: "bar.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expectedDocs, ignoreScore) {
+		t.Errorf("index.Search(`ciao`) mismatch (-want +got)\n%s", cmp.Diff(expectedDocs, docs, ignoreScore))
+	}
+}
+
+func Test_column_remove_keeps_docs_dense(t *testing.T) {
+	col := NewColumn("hello")
+	col.Upsert(Posting{Doc: 0, Count: 1})
+	col.Upsert(Posting{Doc: 1, Count: 2})
+	col.Upsert(Posting{Doc: 2, Count: 3})
+	col.Remove(0)
+	col.Upsert(Posting{Doc: 1, Count: 0})
+
+	expected := []Posting{{Doc: 2, Count: 3}}
+	if !cmp.Equal(col.Docs, expected) {
+		t.Errorf("col.Docs mismatch (-want +got)\n%s", cmp.Diff(expected, col.Docs))
+	}
+	col.Remove(2)
+	if !col.Empty() {
+		t.Errorf("col.Empty()=false, want true")
+	}
+}
+
+func Test_search_ranks_weighted_fields_above_body_text(t *testing.T) {
+	index := New(2)
+	index.Update(&Document{
+		Name:      "passing.md",
+		WordCount: map[string]int{"docker": 3},
+	})
+	index.Update(&Document{
+		Name:      "docker.md",
+		WordCount: map[string]int{"docker": 2},
+		Fields:    map[string]FieldCounts{"docker": {FieldTitle: 1, FieldBody: 1}},
+	})
+
+	docs, _ := index.Search("docker")
+	expected := DocList{{Document: "docker.md", Count: 2, Rank: 9}, {Document: "passing.md", Count: 3, Rank: 3}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("index.Search(`docker`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
+	}
+
+	scores, _ := Search("docker", index)
+	if len(scores) != 2 || scores[0].Document != "docker.md" {
+		t.Errorf("Search(`docker`)=%v, want docker.md first", scores)
+	}
+}
+
+func Test_bm25_favours_frequent_terms_in_short_documents(t *testing.T) {
+	index := New(3)
+	index.Update(&Document{Name: "once.md", WordCount: map[string]int{"bazel": 1, "maven": 1}})
+	index.Update(&Document{Name: "often.md", WordCount: map[string]int{"bazel": 4, "maven": 1}})
+	index.Update(&Document{Name: "long.md", WordCount: map[string]int{"bazel": 4, "maven": 40}})
+
+	docs, _ := index.Search("bazel")
+	names := make([]string, 0, len(docs))
+	for _, d := range docs {
+		names = append(names, d.Document)
+	}
+	expected := []string{"often.md", "once.md", "long.md"}
+	if !cmp.Equal(names, expected) {
+		t.Errorf("index.Search(`bazel`) order mismatch (-want +got)\n%s", cmp.Diff(expected, names))
+	}
+	if docs[0].Score <= docs[1].Score || docs[1].Score <= docs[2].Score {
+		t.Errorf("index.Search(`bazel`) scores=%v, want strictly decreasing", docs)
+	}
+}
+
+func Test_bm25_saturates_term_frequency(t *testing.T) {
+	index := New(3)
+	index.Update(&Document{Name: "a.md", WordCount: map[string]int{"bazel": 10}})
+	index.Update(&Document{Name: "b.md", WordCount: map[string]int{"bazel": 1000}})
+	index.Update(&Document{Name: "c.md", WordCount: map[string]int{"maven": 1}})
+
+	a := index.bm25(index.Words["bazel"].Docs[0], 2)
+	b := index.bm25(index.Words["bazel"].Docs[1], 2)
+	idf := math.Log(1 + (3-2+0.5)/(2+0.5))
+	if b > idf*(bm25K1+1) {
+		t.Errorf("bm25(b.md)=%v, want <= %v", b, idf*(bm25K1+1))
+	}
+	if b/a > 2 {
+		t.Errorf("bm25(b.md)/bm25(a.md)=%v, want saturation below 2", b/a)
+	}
+}
+
+func Test_search_penalises_fuzzy_matches(t *testing.T) {
+	index := New(2)
+	index.Update(&Document{Name: "exact.md", WordCount: map[string]int{"docker": 1, "other": 9}})
+	index.Update(&Document{Name: "fuzzy.md", WordCount: map[string]int{"dockers": 3}})
+
+	scores, _ := Search("docker dockers", index)
+	if len(scores) != 0 {
+		t.Errorf("Search(`docker dockers`)=%v, want no documents containing both", scores)
+	}
+
+	index.Remove("exact.md")
+	scores, _ = Search("docker", index)
+	if len(scores) != 1 || scores[0].Distance != 1 {
+		t.Fatalf("Search(`docker`)=%v, want fuzzy.md at distance 1", scores)
+	}
+	docs, _ := index.Search("dockers")
+	if scores[0].Score != docs[0].Score*fuzzyPenalty(1) {
+		t.Errorf("Search(`docker`) score=%v, want %v", scores[0].Score, docs[0].Score*fuzzyPenalty(1))
+	}
+}
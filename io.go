@@ -1,65 +1,113 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"os"
-	"path/filepath"
 	"regexp"
-	"strings"
-	"text/scanner"
+	"unicode/utf8"
 )
 
+const (
+	// sniffLen is how much of a file is inspected to decide whether it is text.
+	sniffLen = 16 * 1024
+	// maxLineLen is longer than the lines people write, minified bundles exceed it.
+	maxLineLen = 8 * 1024
+)
+
+// errNotText is returned when a file is binary or minified.
+var errNotText = errors.New("not a text file")
+
 type StopWords map[string]bool
 
-func WordFrequency(filename string, r io.Reader, stopWords StopWords) *Document {
-	wordCount := make(map[string]int)
-	var s scanner.Scanner
-	s.Init(r)
-	s.Filename = filename
-	s.Mode = scanner.ScanIdents | scanner.ScanInts | scanner.ScanFloats
-	for tok := s.Scan(); tok != scanner.EOF; tok = s.Scan() {
-		if tok == scanner.Ident {
-			txt := strings.ToLower(s.TokenText())
-			if stopWords[txt] {
-				continue
-			}
-			c, ok := wordCount[txt]
-			if !ok {
-				c = 0
+// isText returns false if head, the start of a file, holds a NUL byte, invalid UTF-8 or a
+// line longer than maxLineLen. When eof is false head may end part way through a rune.
+func isText(head []byte, eof bool) bool {
+	var line int
+	for _, b := range head {
+		if b == 0 {
+			return false
+		}
+		line++
+		if b == '\n' {
+			line = 0
+		}
+		if line > maxLineLen {
+			return false
+		}
+	}
+	if !eof {
+		// drop a rune cut short by the end of head
+		for i := len(head) - 1; i >= 0 && i >= len(head)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(head[i]) {
+				if !utf8.FullRune(head[i:]) {
+					head = head[:i]
+				}
+				break
 			}
-			wordCount[txt] = c + 1
 		}
 	}
-	return &Document{WordCount: wordCount}
+	return utf8.Valid(head)
 }
 
+// WordFrequency counts the identifiers in source code excluding stop words. The document
+// is named filename.
+func WordFrequency(filename string, r io.Reader, stopWords StopWords) *Document {
+	a := Analyzer{
+		Tokenizer: tokenizeIdents,
+		Filters:   []TokenFilter{LowercaseFilter, StopFilter(stopWords)},
+	}
+	// tokenizeIdents reads until the end of r and never fails
+	doc, _ := a.Analyze(r)
+	doc.Name = filename
+	return doc
+}
+
+// StaleDocuments compares filenames with the fingerprints recorded in index. It returns the
+// files which are new or modified and the indexed documents which no longer exist or can no
+// longer be read.
+func StaleDocuments(index *Index, filenames []string) (changed []string, removed []string) {
+	present := make(map[string]bool, len(filenames))
+	for _, filename := range filenames {
+		present[filename] = true
+		fp, ok := index.Fingerprint(filename)
+		if !ok {
+			changed = append(changed, filename)
+			continue
+		}
+		info, err := os.Stat(filename)
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			present[filename] = false
+			continue
+		}
+		if err != nil || !fp.Matches(info.ModTime().UnixNano(), info.Size()) {
+			changed = append(changed, filename)
+		}
+	}
+
+	for _, name := range index.Documents() {
+		if !present[name] {
+			removed = append(removed, name)
+		}
+	}
+	return changed, removed
+}
+
+// DocumentList returns the files below each start path whose base name matches expr. Paths
+// which cannot be read are skipped and the first of them is returned as the error.
 func DocumentList(start []string, expr string) ([]string, error) {
-	var docs []string
 	re, err := regexp.Compile(expr)
 	if err != nil {
 		return nil, err
 	}
+	corpora := make(Corpora, 0, len(start))
 	for _, s := range start {
-		err = filepath.Walk(s, func(path string, info os.FileInfo, err error) error {
-			if err != nil {
-				log.Printf("prevent panic by handling failure accessing a path %q: %v\n", path, err)
-				return err
-			}
-			if info.IsDir() && info.Name() == "target" {
-				return filepath.SkipDir
-			}
-			if !info.IsDir() && re.MatchString(info.Name()) {
-				info.Sys()
-				docs = append(docs, path)
-			}
-			return nil
-		})
+		corpora = append(corpora, &Corpus{Path: s, Pattern: re, SkipDirs: defaultSkipDirs})
 	}
-	if err != nil {
-		return nil, err
+	docs, skipped := corpora.Documents()
+	if len(skipped) > 0 {
+		return docs, fmt.Errorf("%s: %s", skipped[0].Path, skipped[0].Reason)
 	}
-	fmt.Println(docs[0])
-	return docs, err
+	return docs, nil
 }
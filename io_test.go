@@ -1,6 +1,9 @@
 package main
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -59,3 +62,110 @@ func Test_document_list(t *testing.T) {
 			cmp.Diff(list, expected))
 	}
 }
+
+func Test_stale_documents_reports_changed_and_removed_files(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	same := filepath.Join(dir, "same.md")
+	modified := filepath.Join(dir, "modified.md")
+	added := filepath.Join(dir, "added.md")
+	vanished := filepath.Join(dir, "vanished.md")
+	for _, filename := range []string{same, modified, added, vanished} {
+		err = ioutil.WriteFile(filename, []byte("hello world"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	index := New(4)
+	for _, filename := range []string{same, modified, vanished} {
+		doc, err := builtinLanguages.readFile(filename, Languages{"english"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		index.Update(doc)
+	}
+	index.Update(&Document{Name: filepath.Join(dir, "deleted.md"), WordCount: map[string]int{"ciao": 1}})
+
+	err = ioutil.WriteFile(modified, []byte("hello world, how are you?"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// listed by the walk but gone before it is compared
+	err = os.Remove(vanished)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	changed, removed := StaleDocuments(index, []string{same, modified, added, vanished})
+	if !cmp.Equal(changed, []string{modified, added}) {
+		t.Errorf("StaleDocuments() changed=%v, want [%s %s]", changed, modified, added)
+	}
+	if !cmp.Equal(removed, []string{vanished, filepath.Join(dir, "deleted.md")}) {
+		t.Errorf("StaleDocuments() removed=%v, want [vanished.md deleted.md]", removed)
+	}
+}
+
+func Test_read_file_fingerprints_content(t *testing.T) {
+	t.Parallel()
+	a, err := builtinLanguages.readFile("testdata/2018-04-06-Docker-for-Development.md", Languages{"english"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := builtinLanguages.readFile("testdata/2019-06-20-Maven-to-bazel-prep.md", Languages{"english"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Fingerprint.Size == 0 || a.Fingerprint.ModTime == 0 {
+		t.Errorf("Fingerprint=%+v, want size and modification time", a.Fingerprint)
+	}
+	if a.Fingerprint.Hash == b.Fingerprint.Hash {
+		t.Errorf("Fingerprint.Hash=%x for both documents, want distinct hashes", a.Fingerprint.Hash)
+	}
+}
+
+func Test_is_text(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		head     []byte
+		eof      bool
+		expected bool
+	}{
+		"markdown":     {[]byte("# Hello\n\nwörld\n"), true, true},
+		"empty":        {nil, true, true},
+		"nul":          {[]byte("PNG\x00\x01"), true, false},
+		"invalid utf8": {[]byte("caf\xe9\n"), true, false},
+		"cut rune":     {[]byte("caf\xc3"), false, true},
+		"cut at eof":   {[]byte("caf\xc3"), true, false},
+		"minified":     {[]byte("var a=1;" + strings.Repeat("b=a+1;", maxLineLen)), false, false},
+	}
+	for name, tc := range cases {
+		actual := isText(tc.head, tc.eof)
+		if actual != tc.expected {
+			t.Errorf("%s: isText()=%v, want %v", name, actual, tc.expected)
+		}
+	}
+}
+
+func Test_read_file_skips_binary(t *testing.T) {
+	t.Parallel()
+	f, err := ioutil.TempFile("", "mdindexer*.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"))
+	f.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = builtinLanguages.readFile(f.Name(), Languages{"english"})
+	if err != errNotText {
+		t.Errorf("readFile() error=%v, want %v", err, errNotText)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// autoLanguages are the languages indexed by -lang auto.
+var autoLanguages = []string{"go", "java", "js", "english"}
+
+// Registry holds the languages files can be read as, how their files are recognised and
+// the analyzer of each.
+type Registry struct {
+	patterns  map[string]*regexp.Regexp
+	analyzers map[string]*Analyzer
+	// auto are the languages indexed by -lang auto.
+	auto []string
+}
+
+// builtinLanguages are the languages known without a config, it is never modified.
+var builtinLanguages = NewRegistry()
+
+// NewRegistry returns a registry of the built in languages.
+func NewRegistry() *Registry {
+	r := &Registry{
+		patterns:  make(map[string]*regexp.Regexp, len(filePatterns)),
+		analyzers: make(map[string]*Analyzer, len(analyzers)),
+		auto:      append([]string(nil), autoLanguages...),
+	}
+	for name, pattern := range filePatterns {
+		r.patterns[name] = regexp.MustCompile(pattern)
+		r.analyzers[name] = analyzers[name]
+	}
+	return r
+}
+
+// Languages are the languages of the files to index. Each file is analyzed as the first
+// language whose extension it matches.
+type Languages []string
+
+// ParseLanguages reads a comma separated list of the built in languages or auto for every
+// one of them.
+func ParseLanguages(spec string) (Languages, error) {
+	return builtinLanguages.Parse(spec)
+}
+
+// Parse reads a comma separated list of languages or auto for every language.
+func (r *Registry) Parse(spec string) (Languages, error) {
+	var langs Languages
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "auto" {
+			for _, lang := range r.auto {
+				langs = langs.add(lang)
+			}
+			continue
+		}
+		if !r.Known(name) {
+			return nil, fmt.Errorf("unknown language %q", name)
+		}
+		langs = langs.add(canonicalLanguage(name))
+	}
+	return langs, nil
+}
+
+func (l Languages) add(lang string) Languages {
+	for _, name := range l {
+		if name == lang {
+			return l
+		}
+	}
+	return append(l, lang)
+}
+
+// canonicalLanguage returns the name a language is recorded under, markdown and english
+// are the same language.
+func canonicalLanguage(name string) string {
+	if name == "markdown" {
+		return "english"
+	}
+	return name
+}
+
+// Known returns true if name is a language in the registry.
+func (r *Registry) Known(name string) bool {
+	_, ok := r.patterns[name]
+	return ok
+}
+
+// Pattern returns a regular expression matching the files of every language in langs.
+func (r *Registry) Pattern(langs Languages) string {
+	patterns := make([]string, 0, len(langs))
+	for _, lang := range langs {
+		patterns = append(patterns, "("+r.patterns[lang].String()+")")
+	}
+	return strings.Join(patterns, "|")
+}
+
+// Of returns the language in langs of filename or an empty string if it is in none of them.
+func (r *Registry) Of(langs Languages, filename string) string {
+	for _, lang := range langs {
+		if r.patterns[lang].MatchString(filename) {
+			return lang
+		}
+	}
+	return ""
+}
+
+// Analyzers returns the analyzer of each language in langs.
+func (r *Registry) Analyzers(langs Languages) []*Analyzer {
+	list := make([]*Analyzer, 0, len(langs))
+	for _, lang := range langs {
+		list = append(list, r.analyzers[lang])
+	}
+	return list
+}
+
+// Register adds or replaces a language read with analyzer. Files with one of the
+// extensions are in the language, the existing extensions are kept when there are none.
+// It must be called before any documents are read.
+func (r *Registry) Register(name string, extensions []string, analyzer *Analyzer) {
+	name = canonicalLanguage(name)
+	pattern := r.patterns[name]
+	if len(extensions) > 0 {
+		quoted := make([]string, 0, len(extensions))
+		for _, ext := range extensions {
+			quoted = append(quoted, regexp.QuoteMeta(strings.TrimPrefix(ext, ".")))
+		}
+		pattern = regexp.MustCompile(`\.(` + strings.Join(quoted, "|") + `)$`)
+	}
+
+	names := []string{name}
+	if name == "english" {
+		names = append(names, "markdown")
+	}
+	for _, n := range names {
+		r.patterns[n] = pattern
+		r.analyzers[n] = analyzer
+	}
+	if !containsWord(r.auto, name) {
+		r.auto = append(r.auto, name)
+	}
+}
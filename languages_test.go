@@ -0,0 +1,107 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_parse_languages(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		spec     string
+		expected Languages
+		ok       bool
+	}{
+		"single":    {"go", Languages{"go"}, true},
+		"many":      {"go, java,english", Languages{"go", "java", "english"}, true},
+		"markdown":  {"markdown,english", Languages{"english"}, true},
+		"auto":      {"auto", Languages{"go", "java", "js", "english"}, true},
+		"auto plus": {"english,auto", Languages{"english", "go", "java", "js"}, true},
+		"unknown":   {"go,cobol", nil, false},
+		"empty":     {"", nil, false},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			actual, err := ParseLanguages(tc.spec)
+			if (err == nil) != tc.ok {
+				t.Fatalf("ParseLanguages(%q) error=%v, want ok=%v", tc.spec, err, tc.ok)
+			}
+			if !cmp.Equal(actual, tc.expected) {
+				t.Errorf("ParseLanguages(%q) mismatch (-want +got)\n%s", tc.spec, cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func Test_language_of_file(t *testing.T) {
+	t.Parallel()
+	langs := Languages{"go", "english"}
+	cases := map[string]string{
+		"main.go":         "go",
+		"docs/HOWTO.md":   "english",
+		"README.markdown": "english",
+		"Main.java":       "",
+	}
+	for filename, expected := range cases {
+		if actual := builtinLanguages.Of(langs, filename); actual != expected {
+			t.Errorf("Of(%q)=%q, want %q", filename, actual, expected)
+		}
+	}
+}
+
+func Test_index_many_languages(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{
+		"build.go":   "package main\n\n// building the routes\nfunc building() {}\n",
+		"guide.md":   "# Guide\n\nBuilding the routes is easy.",
+		"Build.java": "class Build { void building() {} }",
+	}
+	for name, text := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(text), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	langs, err := ParseLanguages("go,english")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ix := &Indexer{Corpora: Corpora{NewCorpus(dir, langs)}, Languages: langs, Workers: 2}
+	index := ix.Build()
+	index.SetAnalyzers(builtinLanguages.Analyzers(langs)...)
+
+	cases := map[string][]string{
+		"building":              {"build.go", "guide.md"},
+		`"building the routes"`: {"build.go", "guide.md"},
+		"building lang:go":      {"build.go"},
+		"routes lang:markdown":  {"guide.md"},
+		"sym:building":          {"build.go"},
+	}
+	for query, expected := range cases {
+		result, err := SearchWith(query, index, SearchOptions{Exact: true})
+		if err != nil {
+			t.Errorf("SearchWith(%q) error=%v, want nil", query, err)
+			continue
+		}
+		var actual []string
+		for _, name := range documents(result.Docs) {
+			actual = append(actual, filepath.Base(name))
+		}
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("SearchWith(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, actual))
+		}
+	}
+	if lang := index.Language(filepath.Join(dir, "guide.md")); lang != "english" {
+		t.Errorf("Language(guide.md)=%q, want english", lang)
+	}
+}
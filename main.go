@@ -1,66 +1,292 @@
 package main
 
 import (
+	"bufio"
 	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"io/ioutil"
 	"log"
-	"net/http"
 	"os"
-	"runtime"
+	"os/signal"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
-func filePattern(language string) string {
-	switch language {
-	case "go":
-		return "\\.go$"
-	case "java":
-		return "\\.java$"
-	case "js":
-		return "\\.js$"
-	}
-	return ""
+// filePatterns match the names of the files in each language.
+var filePatterns = map[string]string{
+	"go":       "\\.go$",
+	"java":     "\\.java$",
+	"js":       "\\.js$",
+	"english":  "\\.(md|markdown)$",
+	"markdown": "\\.(md|markdown)$",
 }
 
 func main() {
+	var configFile string
+	var addr string
+	var tlsCert string
+	var tlsKey string
 	var language string
 	var start string
+	var include string
+	var exclude string
+	var maxFileSize int64
+	var followSymlinks bool
+	var indexFile string
+	var watch bool
 
+	flag.StringVar(&configFile, "config", "", "read settings from this JSON file, the other flags override it")
+	flag.StringVar(&addr, "addr", "127.0.0.1:8000", "listen on host:port or unix:path for a Unix domain socket")
+	flag.StringVar(&tlsCert, "tls-cert", "", "serve HTTPS with this PEM certificate, it is reloaded when changed")
+	flag.StringVar(&tlsKey, "tls-key", "", "PEM private key of the -tls-cert certificate")
 	flag.StringVar(&start, "start", ".", "search start")
-	flag.StringVar(&language, "lang", "", "language (e.g. java, go, english)")
+	flag.StringVar(&language, "lang", "auto", "languages separated by commas or auto for all (e.g. java, go, js, english)")
+	flag.StringVar(&include, "include", "", "globs separated by commas of the files to index, a leading ! deselects (e.g. docs/**/*.md,!**/generated/**)")
+	flag.StringVar(&exclude, "exclude", "", "globs separated by commas of the files and directories to skip")
+	flag.Int64Var(&maxFileSize, "max-file-size", defaultMaxFileSize, "size in bytes of the largest file indexed, 0 for any size")
+	flag.BoolVar(&followSymlinks, "follow-symlinks", false, "descend into symbolic links to directories")
+	flag.StringVar(&indexFile, "index-file", "", "save the index to this file and load it on start")
+	flag.BoolVar(&watch, "watch", false, "keep the index current as files change")
 	flag.Parse()
 
-	pattern := filePattern(language)
-	if pattern == "" {
-		log.Fatal("invalid language specified")
+	cfg := DefaultConfig()
+	if configFile != "" {
+		var err error
+		cfg, err = LoadConfig(configFile)
+		if err != nil {
+			log.Fatalf("config=failed error='%v'\n", err)
+		}
+	}
+	set := make(map[string]bool)
+	flag.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+	var starts, languages []string
+	if set["start"] {
+		starts = strings.Split(start, ",")
+	}
+	if set["lang"] {
+		languages = strings.Split(language, ",")
+	}
+	cfg.overrideCorpora(starts, languages)
+	for i := range cfg.Corpora {
+		if set["include"] {
+			cfg.Corpora[i].Include = splitList(include)
+		}
+		if set["exclude"] {
+			cfg.Corpora[i].Exclude = splitList(exclude)
+		}
+		if set["follow-symlinks"] {
+			cfg.Corpora[i].FollowSymlinks = followSymlinks
+		}
+	}
+	if set["addr"] {
+		cfg.Addr = addr
+	}
+	if set["tls-cert"] {
+		cfg.TLS.CertFile = tlsCert
+	}
+	if set["tls-key"] {
+		cfg.TLS.KeyFile = tlsKey
+	}
+	if set["max-file-size"] {
+		cfg.Limits.MaxFileSize = maxFileSize
+	}
+	if set["index-file"] {
+		cfg.IndexFile = indexFile
+	}
+	if set["watch"] {
+		cfg.Watch = watch
+	}
+	err := cfg.Validate()
+	if err != nil {
+		log.Fatalf("config=invalid error='%v'\n", err)
+	}
+	ix, err := cfg.Indexer()
+	if err != nil {
+		log.Fatalf("config=invalid error='%v'\n", err)
+	}
+
+	index := loadIndex(cfg.IndexFile, ix.Analysis)
+	if index == nil {
+		index = ix.Build()
+		saveIndex(cfg.IndexFile, index)
+	} else if ix.Refresh(index) {
+		saveIndex(cfg.IndexFile, index)
+	}
+	index.SetAnalyzers(ix.registry().Analyzers(ix.Languages)...)
+	index.SetLanguages(ix.registry())
+	go refreshOnSignal(index, cfg.IndexFile, ix)
+
+	var watcher *Watcher
+	if cfg.Watch {
+		watcher, err = NewWatcher(index, ix)
+		if err != nil {
+			log.Fatalf("watch=failed start=%v error='%v'\n", ix.Corpora.Paths(), err)
+		}
+	}
+
+	srv, err := newServer(cfg, BuildRoutes(ix, index))
+	if err != nil {
+		log.Fatalf("tls=failed error='%v'\n", err)
+	}
+	ln, err := listen(cfg.Addr)
+	if err != nil {
+		log.Fatalf("listen=failed addr=%s error='%v'\n", cfg.Addr, err)
+	}
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	log.Printf("addr=%s tls=%v\n", cfg.Addr, srv.TLSConfig != nil)
+	err = serve(srv, ln, stop)
+	if err != nil {
+		log.Fatalf("serve=failed error='%v'\n", err)
 	}
 
-	paths := strings.Split(start, ",")
-	var stopWords = make(StopWords)
-	for _, w := range stopWordMap[language] {
-		stopWords[w] = true
+	// changes indexed by the watcher are kept for the next start
+	if watcher != nil {
+		watcher.Close()
+		saveIndex(cfg.IndexFile, index)
 	}
+	log.Println("shutdown=success")
+}
+
+// splitList returns the non-empty items of a comma separated list.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func loadIndex(indexFile string, analysis uint64) *Index {
+	if indexFile == "" {
+		return nil
+	}
+	ts := time.Now()
+	index, err := LoadFile(indexFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		log.Printf("indexLoad=failed file=%s error='%v'\n", indexFile, err)
+		return nil
+	}
+	if index.Analysis() != analysis {
+		log.Printf("indexLoad=stale file=%s reason='language settings changed'\n", indexFile)
+		return nil
+	}
+	log.Printf("indexLoad=success file=%s documents=%d words=%d latency=%v\n", indexFile, index.Len(), index.WordCount(), time.Since(ts))
+	return index
+}
 
+func saveIndex(indexFile string, index *Index) {
+	if indexFile == "" {
+		return
+	}
 	ts := time.Now()
-	filenames, err := DocumentList(paths, pattern)
+	index.Compact()
+	err := SaveFile(indexFile, index)
 	if err != nil {
-		log.Fatalf("glob=failed start=%s pattern=%s error='%v'", start, pattern, err)
+		log.Printf("indexSave=failed file=%s error='%v'\n", indexFile, err)
+		return
+	}
+	log.Printf("indexSave=success file=%s latency=%v\n", indexFile, time.Since(ts))
+}
+
+// Indexer reads the documents in its corpora into an index.
+type Indexer struct {
+	Corpora Corpora
+	// Languages of every corpus, each file is read as the language of its extension.
+	Languages Languages
+	// Registry defines the languages, the built in ones when nil.
+	Registry *Registry
+	// Workers is the number of files read concurrently.
+	Workers int
+	// Analysis is a hash of the language settings, an index saved with others is rebuilt.
+	Analysis uint64
+
+	discoveries discoveries
+}
+
+func (ix *Indexer) registry() *Registry {
+	if ix.Registry == nil {
+		return builtinLanguages
 	}
-	log.Printf("documentList=success start=`%s` pattern=`%s` count=%d\n", start, pattern, len(filenames))
+	return ix.Registry
+}
+
+// documentList walks the corpora starting a new discovery report.
+func (ix *Indexer) documentList() []string {
+	ts := time.Now()
+	filenames, skipped := ix.Corpora.Documents()
+	ix.discoveries.set(Discovery{
+		Time:      ts,
+		Corpora:   ix.Corpora.Paths(),
+		Documents: len(filenames),
+		Skipped:   skipped,
+	})
+	return filenames
+}
 
+// Build returns a new index of every document.
+func (ix *Indexer) Build() *Index {
+	ts := time.Now()
+	filenames := ix.documentList()
 	index := New(len(filenames))
+	index.SetAnalysis(ix.Analysis)
+	ix.indexDocuments(index, filenames)
+	ix.logDiscovery()
+	log.Printf("documents=%d words=%d latency=%v\n", index.Len(), index.WordCount(), time.Since(ts))
+	return index
+}
 
-	fnch := make(chan string, runtime.NumCPU()*4)
-	doch := make(chan *Document, runtime.NumCPU()*4)
+// Refresh re-reads the files which changed since the index was built and drops the
+// documents which no longer exist. It returns true if the index was modified.
+func (ix *Indexer) Refresh(index *Index) bool {
+	ts := time.Now()
+	filenames := ix.documentList()
+	changed, removed := StaleDocuments(index, filenames)
+	for _, name := range removed {
+		index.Remove(name)
+	}
+	ix.indexDocuments(index, changed)
+	ix.logDiscovery()
+	log.Printf("refresh=success changed=%d removed=%d documents=%d words=%d latency=%v\n",
+		len(changed), len(removed), index.Len(), index.WordCount(), time.Since(ts))
+	return len(changed) > 0 || len(removed) > 0
+}
+
+// refreshOnSignal refreshes the index each time the process receives SIGHUP.
+func refreshOnSignal(index *Index, indexFile string, ix *Indexer) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	for range ch {
+		if ix.Refresh(index) {
+			saveIndex(indexFile, index)
+		}
+	}
+}
+
+func (ix *Indexer) indexDocuments(index *Index, filenames []string) {
+	fnch := make(chan string, ix.Workers*2)
+	doch := make(chan *Document, ix.Workers*2)
 
 	var docClose sync.Once
 	var wg sync.WaitGroup
-	for i := 0; i < runtime.NumCPU()*2; i++ {
-		go readDoc(fnch, doch, &wg, &docClose, stopWords)
+	for i := 0; i < ix.Workers; i++ {
+		wg.Add(1)
+		go ix.readDoc(fnch, doch, &wg, &docClose)
 	}
 
 	var wgig sync.WaitGroup
+	wgig.Add(1)
 	go updateIndex(doch, index, &wgig)
 
 	for _, filename := range filenames {
@@ -69,23 +295,15 @@ func main() {
 	close(fnch)
 	wg.Wait()
 	wgig.Wait()
-
-	log.Printf("documents=%d words=%d latency=%v\n", index.Capacity(), index.WordCount(), time.Since(ts))
-
-	mux := BuildRoutes(paths, index)
-	log.Println("addr=127.0.0.1:8000")
-	err = http.ListenAndServe("127.0.0.1:8000", mux)
-	if err != nil {
-		log.Fatalf("listen=failed error='%v'\n", err)
-	}
 }
 
-func readDoc(fnch chan string, doch chan *Document, wg *sync.WaitGroup, docClose *sync.Once, stopWords StopWords) {
-	wg.Add(1)
+// readDoc reads the files from fnch, those which cannot be read are added to the
+// discovery report.
+func (ix *Indexer) readDoc(fnch chan string, doch chan *Document, wg *sync.WaitGroup, docClose *sync.Once) {
 	for filename := range fnch {
-		doc, err := readFile(filename, stopWords)
+		doc, err := ix.registry().readFile(filename, ix.Languages)
 		if err != nil {
-			log.Printf("readFile=failed filename=%s error='%v'\n", filename, err)
+			ix.discoveries.skip(filename, err)
 			continue
 		}
 		doch <- doc
@@ -98,7 +316,6 @@ func readDoc(fnch chan string, doch chan *Document, wg *sync.WaitGroup, docClose
 }
 
 func updateIndex(ch chan *Document, index *Index, wgig *sync.WaitGroup) {
-	wgig.Add(1)
 	for doc := range ch {
 		index.Update(doc)
 	}
@@ -257,20 +474,65 @@ var javaStopWords = []string{
 }
 var englishStopWords = []string{"a", "about", "above", "above", "across", "after", "afterwards", "again", "against", "all", "almost", "alone", "along", "already", "also", "although", "always", "am", "among", "amongst", "amoungst", "amount", "an", "and", "another", "any", "anyhow", "anyone", "anything", "anyway", "anywhere", "are", "around", "as", "at", "back", "be", "became", "because", "become", "becomes", "becoming", "been", "before", "beforehand", "behind", "being", "below", "beside", "besides", "between", "beyond", "bill", "both", "bottom", "but", "by", "call", "can", "cannot", "cant", "co", "con", "could", "couldnt", "cry", "de", "describe", "detail", "do", "done", "down", "due", "during", "each", "eg", "eight", "either", "eleven", "else", "elsewhere", "empty", "enough", "etc", "even", "ever", "every", "everyone", "everything", "everywhere", "except", "few", "fifteen", "fify", "fill", "find", "fire", "first", "five", "for", "former", "formerly", "forty", "found", "four", "from", "front", "full", "further", "get", "give", "had", "has", "hasnt", "have", "he", "hence", "her", "here", "hereafter", "hereby", "herein", "hereupon", "hers", "herself", "him", "himself", "his", "how", "however", "hundred", "ie", "if", "in", "inc", "indeed", "interest", "into", "is", "it", "its", "itself", "keep", "last", "latter", "latterly", "least", "less", "ltd", "made", "many", "may", "me", "meanwhile", "might", "mill", "mine", "more", "moreover", "most", "mostly", "move", "much", "must", "my", "myself", "name", "namely", "neither", "never", "nevertheless", "next", "nine", "no", "nobody", "none", "noone", "nor", "not", "nothing", "now", "nowhere", "of", "off", "often", "on", "once", "one", "only", "onto", "or", "other", "others", "otherwise", "our", "ours", "ourselves", "out", "over", "own", "part", "per", "perhaps", "please", "put", "rather", "re", "same", "see", "seem", "seemed", "seeming", "seems", "serious", "several", "she", "should", "show", "side", "since", "sincere", "six", "sixty", "so", "some", "somehow", "someone", "something", "sometime", "sometimes", "somewhere", "still", "such", "system", "take", "ten", "than", "that", "the", "their", "them", "themselves", "then", "thence", "there", "thereafter", "thereby", "therefore", "therein", "thereupon", "these", "they", "thickv", "thin", "third", "this", "those", "though", "three", "through", "throughout", "thru", "thus", "to", "together", "too", "top", "toward", "towards", "twelve", "twenty", "two", "un", "under", "until", "up", "upon", "us", "very", "via", "was", "we", "well", "were", "what", "whatever", "when", "whence", "whenever", "where", "whereafter", "whereas", "whereby", "wherein", "whereupon", "wherever", "whether", "which", "while", "whither", "who", "whoever", "whole", "whom", "whose", "why", "will", "with", "within", "without", "would", "yet", "you", "your", "yours", "yourself", "yourselves"}
 
-var stopWordMap = map[string][]string{
+// defaultStopWords are the words each language never indexes.
+var defaultStopWords = map[string][]string{
 	"english": englishStopWords,
 	"go":      goStopWords,
 	"java":    javaStopWords,
 	"js":      jsStopWords,
 }
 
-func readFile(filename string, stopWords StopWords) (*Document, error) {
+// analyzers configures how the files of each language are indexed and queried.
+var analyzers = map[string]*Analyzer{
+	"english":  englishAnalyzer(englishStopWords, true),
+	"go":       goAnalyzer(goStopWords),
+	"java":     codeAnalyzer(javaStopWords, lexJava(javaStopWords)),
+	"js":       codeAnalyzer(jsStopWords, lexJS(jsStopWords)),
+	"markdown": englishAnalyzer(englishStopWords, true),
+}
+
+// readFile analyzes filename as the language in langs its extension belongs to.
+func (reg *Registry) readFile(filename string, langs Languages) (*Document, error) {
+	lang := reg.Of(langs, filename)
+	analyzer := reg.analyzers[lang]
+	if analyzer == nil {
+		return nil, fmt.Errorf("no language matches %s", filename)
+	}
 	r, err := os.Open(filename)
 	if err != nil {
 		return nil, err
 	}
 	defer r.Close()
-	doc := WordFrequency(filename, r, stopWords)
+	info, err := r.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	br := bufio.NewReaderSize(r, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	if !isText(head, err == io.EOF) {
+		return nil, errNotText
+	}
+
+	h := fnv.New64a()
+	tee := io.TeeReader(br, h)
+	doc, err := analyzer.Analyze(tee)
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.Copy(ioutil.Discard, tee)
+	if err != nil {
+		return nil, err
+	}
 	doc.Name = filename
+	doc.Language = lang
+	doc.Fingerprint = Fingerprint{
+		ModTime: info.ModTime().UnixNano(),
+		Size:    info.Size(),
+		Hash:    h.Sum64(),
+	}
 	return doc, nil
 }
@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxLineLength is the longest markdown line that will be tokenised.
+const maxLineLength = 1024 * 1024
+
+var frontMatterKey = regexp.MustCompile(`^([A-Za-z0-9_-]+):\s*`)
+
+// frontMatterFields maps front matter keys to the field their values are indexed in.
+var frontMatterFields = map[string]Field{
+	"title":      FieldTitle,
+	"tags":       FieldTag,
+	"keywords":   FieldTag,
+	"categories": FieldTag,
+}
+
+// emitFunc receives each word and the field it was found in.
+type emitFunc func(word string, field Field)
+
+// tokenizeMarkdown emits the words in a markdown document. It understands front matter,
+// headings, links, inline code and fenced code blocks and keeps contractions and hyphenated
+// words intact. Each word is emitted with the field it occurs in. A line longer than
+// maxLineLength is an error rather than the end of the document.
+func tokenizeMarkdown(r io.Reader, emit emitFunc) error {
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 0, 64*1024), maxLineLength)
+
+	var fence string
+	var frontMatter bool
+	var frontMatterField Field
+	for line := 0; s.Scan(); line++ {
+		text := s.Text()
+		trimmed := strings.TrimSpace(text)
+		switch {
+		case line == 0 && trimmed == "---":
+			frontMatter = true
+
+		case frontMatter:
+			if trimmed == "---" || trimmed == "..." {
+				frontMatter = false
+				continue
+			}
+			// only values are indexed, keys such as layout or title are noise
+			if m := frontMatterKey.FindStringSubmatch(text); m != nil {
+				frontMatterField = frontMatterFields[strings.ToLower(m[1])]
+				text = text[len(m[0]):]
+			}
+			tokenizeProse(text, frontMatterField, emit)
+
+		case fence != "":
+			if isFenceClose(text, fence) {
+				fence = ""
+				continue
+			}
+			tokenizeCode(text, emit)
+
+		default:
+			fence = openFence(text)
+			if fence != "" {
+				continue
+			}
+			text, field := headingField(trimmed)
+			tokenizeInline(text, field, emit)
+		}
+	}
+	return s.Err()
+}
+
+// headingField strips an ATX heading marker from line. A level 1 heading is treated as
+// the documents title.
+func headingField(line string) (string, Field) {
+	text := strings.TrimLeft(line, "#")
+	level := len(line) - len(text)
+	if level == 0 || level > 6 || (text != "" && text[0] != ' ' && text[0] != '\t') {
+		return line, FieldBody
+	}
+	if level == 1 {
+		return text, FieldTitle
+	}
+	return text, FieldHeading
+}
+
+// openFence returns the fence marker if line opens a fenced code block.
+func openFence(line string) string {
+	indent := len(line) - len(strings.TrimLeft(line, " "))
+	if indent > 3 {
+		return ""
+	}
+	line = line[indent:]
+	for _, ch := range []byte{'`', '~'} {
+		n := 0
+		for n < len(line) && line[n] == ch {
+			n++
+		}
+		if n >= 3 {
+			return line[:n]
+		}
+	}
+	return ""
+}
+
+func isFenceClose(line string, fence string) bool {
+	marker := openFence(line)
+	return marker != "" && marker[0] == fence[0] && len(marker) >= len(fence) &&
+		strings.TrimSpace(line) == marker
+}
+
+// tokenizeInline tokenises a line of markdown text skipping link destinations and markup.
+func tokenizeInline(line string, field Field, emit emitFunc) {
+	var start int
+	flush := func(end int) {
+		if start < end {
+			tokenizeProse(line[start:end], field, emit)
+		}
+	}
+
+	for i := 0; i < len(line); {
+		switch line[i] {
+		case '`':
+			ticks := 1
+			for i+ticks < len(line) && line[i+ticks] == '`' {
+				ticks++
+			}
+			marker := line[i : i+ticks]
+			end := strings.Index(line[i+ticks:], marker)
+			if end < 0 {
+				i += ticks
+				continue
+			}
+			flush(i)
+			tokenizeCode(line[i+ticks:i+ticks+end], emit)
+			i += ticks + end + ticks
+			start = i
+
+		case ']':
+			// the link text has already been treated as prose, skip the destination
+			flush(i)
+			i++
+			if i < len(line) && (line[i] == '(' || line[i] == ':') {
+				i = skipDestination(line, i)
+			}
+			start = i
+
+		case '<':
+			end := strings.IndexByte(line[i:], '>')
+			if end < 0 || i+1 >= len(line) || !isTagStart(line[i+1]) {
+				i++
+				continue
+			}
+			flush(i)
+			i += end + 1
+			start = i
+
+		default:
+			i++
+		}
+	}
+	flush(len(line))
+}
+
+// skipDestination returns the position after the link destination starting at i.
+func skipDestination(line string, i int) int {
+	if line[i] == ':' {
+		// reference definition, the remainder of the line is the url and title
+		return len(line)
+	}
+	depth := 0
+	for ; i < len(line); i++ {
+		switch line[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i + 1
+			}
+		}
+	}
+	return i
+}
+
+func isTagStart(ch byte) bool {
+	return ch == '/' || ch == '!' || ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z')
+}
+
+// tokenizeProse emits the words in natural language text. Apostrophes and hyphens
+// between letters are kept so "don't" and "double-edged" survive, the parts of
+// hyphenated words are also emitted and bare urls are skipped.
+func tokenizeProse(text string, field Field, emit emitFunc) {
+	for _, token := range strings.Fields(text) {
+		if strings.Contains(token, "://") {
+			continue
+		}
+		var word strings.Builder
+		for i, ch := range token {
+			if unicode.IsLetter(ch) || unicode.IsDigit(ch) {
+				word.WriteRune(ch)
+				continue
+			}
+			if isJoiner(ch) && word.Len() > 0 {
+				next, _ := utf8.DecodeRuneInString(token[i+utf8.RuneLen(ch):])
+				if unicode.IsLetter(next) || unicode.IsDigit(next) {
+					if ch == '’' {
+						ch = '\''
+					}
+					word.WriteRune(ch)
+					continue
+				}
+			}
+			emitProse(word.String(), field, emit)
+			word.Reset()
+		}
+		emitProse(word.String(), field, emit)
+	}
+}
+
+func isJoiner(ch rune) bool {
+	return ch == '\'' || ch == '’' || ch == '-'
+}
+
+func emitProse(word string, field Field, emit emitFunc) {
+	if !hasLetter(word) {
+		return
+	}
+	word = strings.ToLower(word)
+	word = strings.TrimSuffix(word, "'s")
+	emit(word, field)
+	if !strings.Contains(word, "-") {
+		return
+	}
+	for _, part := range strings.Split(word, "-") {
+		if hasLetter(part) {
+			emit(part, field)
+		}
+	}
+}
+
+func hasLetter(word string) bool {
+	for _, ch := range word {
+		if unicode.IsLetter(ch) {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenizeCode emits the identifiers in a line of source code.
+func tokenizeCode(text string, emit emitFunc) {
+	start := -1
+	for i, ch := range text {
+		isIdent := ch == '_' || unicode.IsLetter(ch) || (start >= 0 && unicode.IsDigit(ch))
+		if isIdent {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			emit(strings.ToLower(text[start:i]), FieldCode)
+			start = -1
+		}
+	}
+	if start >= 0 {
+		emit(strings.ToLower(text[start:]), FieldCode)
+	}
+}
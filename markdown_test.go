@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const markdownDoc = "---\n" +
+	"title: Docker for Development\n" +
+	"description:\n" +
+	"  Containers are here to stay\n" +
+	"tags: docker\n" +
+	"---\n" +
+	"\n" +
+	"## What we’re baking\n" +
+	"\n" +
+	"A double-edged sword, don't use `docker-compose` from [Heroku's post](https://devcenter.heroku.com/articles/local).\n" +
+	"See <a href=\"https://example.com\">example</a> or https://example.com/raw.\n" +
+	"\n" +
+	"```bash\n" +
+	"docker run --rm my_image:2018\n" +
+	"```\n" +
+	"[ref]: https://example.com/ref\n"
+
+// markdownFrequency counts the words in text without stemming.
+func markdownFrequency(text string, stopWords StopWords) *Document {
+	a := Analyzer{Tokenizer: tokenizeMarkdown, Filters: []TokenFilter{StopFilter(stopWords)}}
+	// reading from a string only fails on a line longer than maxLineLength
+	doc, _ := a.Analyze(strings.NewReader(text))
+	return doc
+}
+
+func Test_markdown_frequency(t *testing.T) {
+	t.Parallel()
+	doc := markdownFrequency(markdownDoc, StopWords{"a": true, "or": true})
+	expected := map[string]int{
+		"docker":       4,
+		"for":          1,
+		"development":  1,
+		"containers":   1,
+		"are":          1,
+		"here":         1,
+		"to":           1,
+		"stay":         1,
+		"what":         1,
+		"we're":        1,
+		"baking":       1,
+		"double-edged": 1,
+		"double":       1,
+		"edged":        1,
+		"sword":        1,
+		"don't":        1,
+		"use":          1,
+		"compose":      1,
+		"from":         1,
+		"heroku":       1,
+		"post":         1,
+		"see":          1,
+		"example":      1,
+		"run":          1,
+		"rm":           1,
+		"my_image":     1,
+		"ref":          1,
+	}
+	if !cmp.Equal(doc.WordCount, expected) {
+		t.Errorf("markdownFrequency(markdownDoc) mismatch (-want +got)\n%s", cmp.Diff(expected, doc.WordCount))
+	}
+}
+
+func Test_markdown_unterminated_fence_is_code(t *testing.T) {
+	t.Parallel()
+	doc := markdownFrequency("~~~~\ndon't stop\n~~~\n", make(StopWords))
+	expected := map[string]int{"don": 1, "t": 1, "stop": 1}
+	if !cmp.Equal(doc.WordCount, expected) {
+		t.Errorf("markdownFrequency() mismatch (-want +got)\n%s", cmp.Diff(expected, doc.WordCount))
+	}
+}
+
+func Test_english_analyzer_stems_prose(t *testing.T) {
+	t.Parallel()
+	r := strings.NewReader("Building builds with the builder, don't")
+	doc, err := analyzers["markdown"].Analyze(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{"build": 2, "builder": 1, "don't": 1}
+	if !cmp.Equal(doc.WordCount, expected) {
+		t.Errorf("Analyze() WordCount mismatch (-want +got)\n%s", cmp.Diff(expected, doc.WordCount))
+	}
+}
+
+func Test_markdown_line_too_long(t *testing.T) {
+	t.Parallel()
+	text := "# Start\n" + strings.Repeat("x", maxLineLength+1) + "\nend\n"
+	_, err := analyzers["markdown"].Analyze(strings.NewReader(text))
+	if err != bufio.ErrTooLong {
+		t.Errorf("Analyze() error=%v, want %v", err, bufio.ErrTooLong)
+	}
+}
+
+func Test_markdown_frequency_records_fields(t *testing.T) {
+	t.Parallel()
+	doc := markdownFrequency(markdownDoc, make(StopWords))
+	cases := map[string]FieldCounts{
+		"docker":      {FieldTitle: 1, FieldCode: 2, FieldTag: 1},
+		"containers":  {FieldBody: 1},
+		"baking":      {FieldHeading: 1},
+		"heroku":      {FieldBody: 1},
+		"development": {FieldTitle: 1},
+	}
+	for word, expected := range cases {
+		if doc.Fields[word] != expected {
+			t.Errorf("Fields[%s]=%v, want %v", word, doc.Fields[word], expected)
+		}
+	}
+}
+
+func Test_heading_field(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		text  string
+		field Field
+	}{
+		"# Title":     {" Title", FieldTitle},
+		"### Section": {" Section", FieldHeading},
+		"#hashtag":    {"#hashtag", FieldBody},
+		"plain":       {"plain", FieldBody},
+	}
+	for line, tc := range cases {
+		text, field := headingField(line)
+		if text != tc.text || field != tc.field {
+			t.Errorf("headingField(%q)=%q, %v, want %q, %v", line, text, field, tc.text, tc.field)
+		}
+	}
+}
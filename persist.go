@@ -0,0 +1,114 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// IndexFormatVersion is incremented whenever the serialised layout of Index or the way
+// words are analyzed changes.
+const IndexFormatVersion uint32 = 12
+
+// indexMagic prefixes every index file so arbitrary files are not decoded as an index.
+const indexMagic = "MDIX"
+
+var (
+	ErrIndexFormat  = fmt.Errorf("file is not an index")
+	ErrIndexVersion = fmt.Errorf("index format version mismatch")
+)
+
+// headerLen is the length of the magic, format version and analysis hash.
+const headerLen = len(indexMagic) + 4 + 8
+
+// Save writes the version header followed by the msgpack encoded index to w.
+func (z *Index) Save(w io.Writer) error {
+	z.RLock()
+	defer z.RUnlock()
+
+	var header [headerLen]byte
+	copy(header[:], indexMagic)
+	binary.BigEndian.PutUint32(header[len(indexMagic):], IndexFormatVersion)
+	binary.BigEndian.PutUint64(header[len(indexMagic)+4:], z.analysis)
+	_, err := w.Write(header[:])
+	if err != nil {
+		return err
+	}
+
+	mw := msgp.NewWriter(w)
+	err = z.EncodeMsg(mw)
+	if err != nil {
+		return err
+	}
+	return mw.Flush()
+}
+
+// Load reads an index previously written by Save. Files written with a different
+// format version are rejected with ErrIndexVersion.
+func Load(r io.Reader) (*Index, error) {
+	var header [headerLen]byte
+	_, err := io.ReadFull(r, header[:])
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return nil, ErrIndexFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	if string(header[:len(indexMagic)]) != indexMagic {
+		return nil, ErrIndexFormat
+	}
+	version := binary.BigEndian.Uint32(header[len(indexMagic):])
+	if version != IndexFormatVersion {
+		return nil, fmt.Errorf("%w: got %d, want %d", ErrIndexVersion, version, IndexFormatVersion)
+	}
+
+	index := New(0)
+	index.analysis = binary.BigEndian.Uint64(header[len(indexMagic)+4:])
+	err = index.DecodeMsg(msgp.NewReader(r))
+	if err != nil {
+		return nil, err
+	}
+	index.rebuild()
+	return index, nil
+}
+
+// SaveFile atomically replaces filename with the serialised index.
+func SaveFile(filename string, index *Index) error {
+	tmp := filename + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(f)
+	err = index.Save(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	closeErr := f.Close()
+	if err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
+
+// LoadFile reads the index stored in filename.
+func LoadFile(filename string) (*Index, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(bufio.NewReader(f))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_saved_index_can_be_loaded_and_searched(t *testing.T) {
+	index := New(20)
+	index.Update(fooMD())
+	index.Update(barMD())
+
+	var buf bytes.Buffer
+	err := index.Save(&buf)
+	if err != nil {
+		t.Fatalf("index.Save() error=%v, want nil", err)
+	}
+
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error=%v, want nil", err)
+	}
+
+	docs, _ := loaded.Search("world")
+	expected := DocList{{Document: "foo.md", Count: 1, Rank: 1}, {Document: "bar.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("loaded.Search(`world`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
+	}
+}
+
+func Test_loaded_index_removes_words_on_update(t *testing.T) {
+	index := New(20)
+	index.Update(bazMD("ciao", "world"))
+	index.Update(barMD())
+
+	var buf bytes.Buffer
+	_ = index.Save(&buf)
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error=%v, want nil", err)
+	}
+	loaded.Update(bazMD("world"))
+
+	docs, _ := loaded.Search("ciao")
+	expected := DocList{{Document: "bar.md", Count: 1, Rank: 1}}
+	if !cmp.Equal(docs, expected, ignoreScore) {
+		t.Errorf("loaded.Search(`ciao`) mismatch (-want +got)\n%s", cmp.Diff(expected, docs, ignoreScore))
+	}
+}
+
+func Test_load_rejects_other_format_versions(t *testing.T) {
+	var buf bytes.Buffer
+	_ = New(1).Save(&buf)
+	b := buf.Bytes()
+	b[len(indexMagic)+3]++
+
+	_, err := Load(bytes.NewReader(b))
+	if !errors.Is(err, ErrIndexVersion) {
+		t.Errorf("Load() error=%v, want ErrIndexVersion", err)
+	}
+}
+
+func Test_load_returns_saved_analysis(t *testing.T) {
+	index := New(1)
+	index.SetAnalysis(42)
+	var buf bytes.Buffer
+	_ = index.Save(&buf)
+
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error=%v, want nil", err)
+	}
+	if loaded.Analysis() != 42 {
+		t.Errorf("loaded.Analysis()=%d, want 42", loaded.Analysis())
+	}
+}
+
+func Test_load_rejects_files_without_header(t *testing.T) {
+	cases := map[string][]byte{
+		"empty":   {},
+		"short":   []byte("MD"),
+		"unknown": []byte("# Hello World\n"),
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(bytes.NewReader(tc))
+			if err != ErrIndexFormat {
+				t.Errorf("Load() error=%v, want ErrIndexFormat", err)
+			}
+		})
+	}
+}
+
+func Test_save_file_round_trip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "index.msgp")
+	index := New(20)
+	index.Update(fooMD())
+	err = SaveFile(filename, index)
+	if err != nil {
+		t.Fatalf("SaveFile() error=%v, want nil", err)
+	}
+
+	loaded, err := LoadFile(filename)
+	if err != nil {
+		t.Fatalf("LoadFile() error=%v, want nil", err)
+	}
+	if !cmp.Equal(loaded.Names, index.Names) {
+		t.Errorf("loaded.Names mismatch (-want +got)\n%s", cmp.Diff(index.Names, loaded.Names))
+	}
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"encoding/binary"
+	"regexp"
+	"sort"
+)
+
+// encodePositions delta encodes ascending token positions as a sequence of uvarints.
+func encodePositions(positions []int) []byte {
+	if len(positions) == 0 {
+		return nil
+	}
+	b := make([]byte, 0, len(positions)*2)
+	var buf [binary.MaxVarintLen64]byte
+	var prev int
+	for _, pos := range positions {
+		n := binary.PutUvarint(buf[:], uint64(pos-prev))
+		b = append(b, buf[:n]...)
+		prev = pos
+	}
+	return b
+}
+
+// decodePositions reverses encodePositions.
+func decodePositions(b []byte) []int {
+	positions := make([]int, 0, len(b))
+	var pos int
+	for len(b) > 0 {
+		delta, n := binary.Uvarint(b)
+		if n <= 0 {
+			break
+		}
+		pos += int(delta)
+		positions = append(positions, pos)
+		b = b[n:]
+	}
+	return positions
+}
+
+// Phrase returns the documents containing terms as consecutive words. Terms are analyzed
+// like the documents were, empty terms and stop words are gaps that match any word. Only
+// exact words are matched.
+func (z *Index) Phrase(terms []string) (DocList, error) {
+	z.RLock()
+	defer z.RUnlock()
+	if 1 > len(z.Words) {
+		return nil, ErrWordNotIndexed
+	}
+	return z.phrase(terms, anyField), nil
+}
+
+// InField returns the documents containing terms in field, as consecutive words when
+// there is more than one term.
+func (z *Index) InField(field Field, terms []string) (DocList, error) {
+	z.RLock()
+	defer z.RUnlock()
+	if 1 > len(z.Words) {
+		return nil, ErrWordNotIndexed
+	}
+	return z.phrase(terms, field), nil
+}
+
+// anyField matches a word wherever it occurs.
+const anyField Field = -1
+
+func (z *Index) phrase(terms []string, field Field) DocList {
+	var lists []DocList
+	for _, term := range z.termFuncs() {
+		lists = append(lists, z.phraseWith(terms, field, term))
+	}
+	return mergeDocs(lists)
+}
+
+// phraseWith matches the phrase with its terms analyzed by term.
+func (z *Index) phraseWith(terms []string, field Field, term TokenFilter) DocList {
+	var offsets []int
+	var cols []*WordColumn
+	for offset, t := range terms {
+		t = term(t)
+		if t == "" {
+			continue
+		}
+		col, ok := z.Words[t]
+		if !ok {
+			return DocList{}
+		}
+		offsets = append(offsets, offset)
+		cols = append(cols, col)
+	}
+
+	return z.positional(cols, field, func(positions [][]int) int {
+		var count int
+		for _, first := range positions[0] {
+			start := first - offsets[0]
+			matched := true
+			for i := 1; i < len(positions); i++ {
+				if !containsPosition(positions[i], start+offsets[i]) {
+					matched = false
+					break
+				}
+			}
+			if matched {
+				count++
+			}
+		}
+		return count
+	})
+}
+
+// Near returns the documents where a and b occur within distance words of each other in
+// either order. Both words are analyzed like the documents were.
+func (z *Index) Near(a, b string, distance int) (DocList, error) {
+	z.RLock()
+	defer z.RUnlock()
+	if 1 > len(z.Words) {
+		return nil, ErrWordNotIndexed
+	}
+
+	var lists []DocList
+	for _, term := range z.termFuncs() {
+		lists = append(lists, z.nearWith(term(a), term(b), distance))
+	}
+	return mergeDocs(lists), nil
+}
+
+func (z *Index) nearWith(a, b string, distance int) DocList {
+	colA, okA := z.Words[a]
+	colB, okB := z.Words[b]
+	if !okA || !okB {
+		return DocList{}
+	}
+
+	return z.positional([]*WordColumn{colA, colB}, anyField, func(positions [][]int) int {
+		var count int
+		for _, pa := range positions[0] {
+			for _, pb := range positions[1] {
+				d := pa - pb
+				if d < 0 {
+					d = -d
+				}
+				if d > 0 && d <= distance {
+					count++
+				}
+			}
+		}
+		return count
+	})
+}
+
+// mergeDocs combines the documents matched by each analyzer keeping the best score of
+// each document.
+func mergeDocs(lists []DocList) DocList {
+	if len(lists) == 1 {
+		return lists[0]
+	}
+	pos := make(map[string]int)
+	docs := DocList{}
+	for _, list := range lists {
+		for _, d := range list {
+			i, ok := pos[d.Document]
+			if !ok {
+				pos[d.Document] = len(docs)
+				docs = append(docs, d)
+				continue
+			}
+			if d.Score > docs[i].Score {
+				docs[i] = d
+			}
+		}
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score > docs[j].Score
+	})
+	return docs
+}
+
+// positional scores the documents containing every column by the number of matches
+// count finds in their decoded positions. Unless field is anyField every word must occur
+// in field.
+func (z *Index) positional(cols []*WordColumn, field Field, count func(positions [][]int) int) DocList {
+	if len(cols) == 0 {
+		return DocList{}
+	}
+
+	// the rarest word limits the candidates to check
+	rarest := 0
+	for i, col := range cols {
+		if len(col.Docs) < len(cols[rarest].Docs) {
+			rarest = i
+		}
+	}
+
+	var matches []Posting
+	for _, candidate := range cols[rarest].Docs {
+		positions := make([][]int, len(cols))
+		found := true
+		for i, col := range cols {
+			p, ok := col.find(candidate.Doc)
+			if !ok || (field != anyField && p.Fields[field] == 0) {
+				found = false
+				break
+			}
+			positions[i] = decodePositions(p.Positions)
+		}
+		if !found {
+			continue
+		}
+		n := count(positions)
+		if n > 0 {
+			matches = append(matches, Posting{Doc: candidate.Doc, Count: n, Fields: FieldCounts{FieldBody: n}})
+		}
+	}
+
+	docs := make(DocList, 0, len(matches))
+	for _, p := range matches {
+		docs = append(docs, DocRelevance{
+			Document: z.byId(p.Doc),
+			Count:    p.Count,
+			Rank:     p.Fields.Weight(),
+			Score:    z.bm25(p, len(matches)),
+		})
+	}
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score > docs[j].Score
+	})
+	return docs
+}
+
+func containsPosition(positions []int, pos int) bool {
+	i := sort.SearchInts(positions, pos)
+	return i < len(positions) && positions[i] == pos
+}
+
+// nearOperator matches the proximity operator in queries such as `maven NEAR/3 bazel`.
+var nearOperator = regexp.MustCompile(`^(?i)near/([0-9]+)$`)
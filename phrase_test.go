@@ -0,0 +1,120 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_positions_round_trip(t *testing.T) {
+	cases := map[string][]int{
+		"empty":  {},
+		"single": {7},
+		"many":   {0, 1, 5, 300, 70000},
+	}
+	for name, positions := range cases {
+		positions := positions
+		t.Run(name, func(t *testing.T) {
+			actual := decodePositions(encodePositions(positions))
+			if !cmp.Equal(actual, positions) {
+				t.Errorf("decodePositions(encodePositions(%v))=%v", positions, actual)
+			}
+		})
+	}
+}
+
+func phraseIndex() *Index {
+	index := New(3)
+	analyzer := &Analyzer{
+		Tokenizer: tokenizeMarkdown,
+		Filters:   []TokenFilter{StopFilter(StopWords{"to": true, "the": true})},
+	}
+	docs := map[string]string{
+		"maven.md": "Migrating from maven to bazel is easy.",
+		"bazel.md": "Bazel replaced maven. Moving to bazel took a week.",
+		"error.md": "error: file not found\nthe file was not found",
+	}
+	for name, text := range docs {
+		doc, _ := analyzer.Analyze(strings.NewReader(text))
+		doc.Name = name
+		index.Update(doc)
+	}
+	index.SetAnalyzers(analyzer)
+	return index
+}
+
+func Test_phrase_matches_consecutive_words(t *testing.T) {
+	index := phraseIndex()
+	cases := map[string]struct {
+		terms    []string
+		expected []string
+	}{
+		"stop word gap":    {[]string{"maven", "to", "bazel"}, []string{"maven.md"}},
+		"explicit gap":     {[]string{"moving", "", "bazel"}, []string{"bazel.md"}},
+		"error message":    {[]string{"file", "not", "found"}, []string{"error.md"}},
+		"wrong order":      {[]string{"bazel", "maven"}, []string{}},
+		"missing word":     {[]string{"maven", "gradle"}, []string{}},
+		"single word":      {[]string{"week"}, []string{"bazel.md"}},
+		"only stop words":  {[]string{"to", "the"}, []string{}},
+		"across sentences": {[]string{"maven", "moving"}, []string{"bazel.md"}},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			docs, err := index.Phrase(tc.terms)
+			if err != nil {
+				t.Fatalf("index.Phrase(%v) error=%v, want nil", tc.terms, err)
+			}
+			names := []string{}
+			for _, d := range docs {
+				names = append(names, d.Document)
+			}
+			if !cmp.Equal(names, tc.expected) {
+				t.Errorf("index.Phrase(%v)=%v, want %v", tc.terms, names, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_phrase_counts_every_occurrence(t *testing.T) {
+	index := phraseIndex()
+	docs, _ := index.Phrase([]string{"not", "found"})
+	if len(docs) != 1 || docs[0].Count != 2 {
+		t.Errorf("index.Phrase(not found)=%v, want error.md with count 2", docs)
+	}
+}
+
+func Test_near_matches_words_within_distance_in_any_order(t *testing.T) {
+	index := phraseIndex()
+	cases := map[string]struct {
+		a, b     string
+		distance int
+		expected int
+	}{
+		"within":     {"migrating", "maven", 2, 1},
+		"reversed":   {"bazel", "migrating", 4, 1},
+		"too far":    {"migrating", "easy", 3, 0},
+		"same word":  {"bazel", "bazel", 10, 1},
+		"both docs":  {"maven", "bazel", 2, 2},
+		"not found":  {"gradle", "maven", 10, 0},
+		"stop words": {"file", "found", 2, 1},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			docs, _ := index.Near(tc.a, tc.b, tc.distance)
+			if len(docs) != tc.expected {
+				t.Errorf("index.Near(%s, %s, %d)=%v, want %d documents", tc.a, tc.b, tc.distance, docs, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_search_supports_phrases(t *testing.T) {
+	index := phraseIndex()
+	scores, _ := Search(`"maven to bazel"`, index)
+	if len(scores) != 1 || scores[0].Document != "maven.md" {
+		t.Errorf("Search(\"maven to bazel\")=%v, want maven.md", scores)
+	}
+}
@@ -0,0 +1,804 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nfisher/mdindexer/edit"
+)
+
+// QueryError describes a query which could not be parsed. Pos is the byte offset in the
+// query where the problem was found.
+type QueryError struct {
+	Pos     int
+	Message string
+	// Param names the search parameter other than the query which is invalid, Pos is
+	// unused when it is set.
+	Param string `json:",omitempty"`
+}
+
+func (e *QueryError) Error() string {
+	if e.Param != "" {
+		return e.Message
+	}
+	return fmt.Sprintf("position %d: %s", e.Pos, e.Message)
+}
+
+// AutoDistance scales the maximum edit distance of fuzzy matches with the word length.
+const AutoDistance = -1
+
+// SearchOptions controls how a word is matched.
+type SearchOptions struct {
+	// MaxDistance is the furthest edit distance of a fuzzy match or AutoDistance.
+	MaxDistance int
+	// AlwaysExpand includes fuzzy matches even when the word itself is indexed.
+	AlwaysExpand bool
+	// Exact disables fuzzy matching.
+	Exact bool
+}
+
+// DefaultSearchOptions falls back to fuzzy matching when a word is not indexed.
+var DefaultSearchOptions = SearchOptions{MaxDistance: AutoDistance}
+
+// maxDistance returns the furthest edit distance allowed for fuzzy matches of word.
+// Substitutions count as two edits so the automatic distance tolerates no typos in
+// words of up to two letters, one in words of up to five and two in longer words.
+func (o SearchOptions) maxDistance(word string) int {
+	if o.Exact {
+		return 0
+	}
+	if o.MaxDistance != AutoDistance {
+		return o.MaxDistance
+	}
+	n := utf8.RuneCountInString(word)
+	switch {
+	case n <= 2:
+		return 0
+	case n <= 5:
+		return 2
+	}
+	return 4
+}
+
+// queryFilters are the fields which restrict results by document metadata or match words
+// in a field.
+var queryFilters = map[string]bool{
+	"path":    true,
+	"ext":     true,
+	"lang":    true,
+	"comment": true,
+	"string":  true,
+	"sym":     true,
+}
+
+// queryFields are the filters matching words in a field of source code.
+var queryFields = map[string]Field{
+	"comment": FieldComment,
+	"string":  FieldString,
+}
+
+type tokenKind int
+
+const (
+	tokenWord tokenKind = iota
+	tokenPhrase
+	tokenOpen
+	tokenClose
+	tokenEOF
+)
+
+type queryToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// lexQuery splits a query into words, quoted phrases and parentheses.
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	for i := 0; i < len(query); {
+		switch ch := query[i]; {
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		case ch == '(':
+			tokens = append(tokens, queryToken{tokenOpen, "(", i})
+			i++
+		case ch == ')':
+			tokens = append(tokens, queryToken{tokenClose, ")", i})
+			i++
+		case ch == '"':
+			end := strings.IndexByte(query[i+1:], '"')
+			if end < 0 {
+				return nil, &QueryError{Pos: i, Message: "unterminated phrase"}
+			}
+			tokens = append(tokens, queryToken{tokenPhrase, query[i+1 : i+1+end], i})
+			i += end + 2
+		case (ch == '+' || ch == '-') && i+1 < len(query) && query[i+1] == '"':
+			// the prefix applies to the phrase which follows it
+			tokens = append(tokens, queryToken{tokenWord, query[i : i+1], i})
+			i++
+		default:
+			start := i
+			for i < len(query) && !strings.ContainsRune(" \t\n\r()", rune(query[i])) {
+				// a filter value may be quoted, e.g. path:"my docs"
+				if query[i] == '"' {
+					end := strings.IndexByte(query[i+1:], '"')
+					if end < 0 {
+						return nil, &QueryError{Pos: i, Message: "unterminated phrase"}
+					}
+					i += end + 2
+					continue
+				}
+				i++
+			}
+			tokens = append(tokens, queryToken{tokenWord, query[start:i], start})
+		}
+	}
+	return append(tokens, queryToken{tokenEOF, "", len(query)}), nil
+}
+
+// queryNode is an element of a parsed query.
+type queryNode interface {
+	eval(e *evaluator) hits
+}
+
+type termNode struct {
+	word string
+}
+
+type wildcardNode struct {
+	pattern string
+}
+
+type phraseNode struct {
+	words []string
+}
+
+type nearNode struct {
+	a, b     string
+	distance int
+}
+
+type filterNode struct {
+	field string
+	value string
+	// re matches the filenames of a lang filter when the documents language is unknown
+	re *regexp.Regexp
+}
+
+// fieldNode matches words occurring in a field, consecutively if there is more than one.
+type fieldNode struct {
+	field Field
+	words []string
+}
+
+// symbolNode matches the documents declaring a symbol.
+type symbolNode struct {
+	name string
+}
+
+type orNode struct {
+	nodes []queryNode
+}
+
+// andNode matches documents satisfying every required node and none of the excluded
+// nodes. Optional nodes only contribute to the score.
+type andNode struct {
+	required []queryNode
+	optional []queryNode
+	excluded []queryNode
+}
+
+// ParseQuery parses the query language:
+//
+//	a b                documents containing both a and b
+//	a OR b             documents containing either a or b
+//	NOT a, -a          documents not containing a
+//	+a b               documents containing a, those which also contain b rank higher
+//	(a OR b) c         parentheses group expressions
+//	"a b c"            the exact phrase
+//	a NEAR/3 b         a within 3 words of b
+//	dock*              words starting with dock, * and ? may be used anywhere in a word
+//	path:docs/         documents whose path contains docs/ or matches a glob
+//	ext:md             documents with the md extension
+//	lang:go            documents written in go
+//	comment:todo       documents with todo in a source code comment
+//	string:"not found" documents with the phrase in a source code string
+//	sym:BuildRoutes    documents declaring BuildRoutes, a method may be written Index.Search
+//
+// AND binds more tightly than OR, the operators must be upper case. Only the built in
+// languages are recognised by lang.
+func ParseQuery(query string) (queryNode, error) {
+	return parseQuery(query, builtinLanguages)
+}
+
+// parseQuery parses query recognising the languages in langs.
+func parseQuery(query string, langs *Registry) (queryNode, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	p := &queryParser{tokens: tokens, langs: langs}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, &QueryError{Pos: tok.pos, Message: fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return node, nil
+}
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	langs  *Registry
+}
+
+func (p *queryParser) peek() queryToken {
+	return p.tokens[p.pos]
+}
+
+func (p *queryParser) next() queryToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func isOperator(tok queryToken, op string) bool {
+	return tok.kind == tokenWord && tok.text == op
+}
+
+func (p *queryParser) parseOr() (queryNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := []queryNode{node}
+	for isOperator(p.peek(), "OR") {
+		p.next()
+		node, err = p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return &orNode{nodes: nodes}, nil
+}
+
+func (p *queryParser) parseAnd() (queryNode, error) {
+	var required, plain, optional, excluded []queryNode
+	for {
+		tok := p.peek()
+		if tok.kind == tokenEOF || tok.kind == tokenClose || isOperator(tok, "OR") {
+			break
+		}
+		if isOperator(tok, "AND") {
+			if len(required)+len(plain)+len(excluded) == 0 {
+				return nil, &QueryError{Pos: tok.pos, Message: "expected a search term before AND"}
+			}
+			p.next()
+			if next := p.peek(); next.kind == tokenEOF || next.kind == tokenClose || isOperator(next, "OR") || isOperator(next, "AND") {
+				return nil, &QueryError{Pos: next.pos, Message: "expected a search term"}
+			}
+			continue
+		}
+
+		switch {
+		case isOperator(tok, "NOT"):
+			p.next()
+			node, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			excluded = append(excluded, node)
+
+		case isPrefixed(tok, p.tokens[p.pos+1]):
+			prefix := tok.text[0]
+			if len(tok.text) == 1 {
+				// the prefix applies to the group or phrase which follows it
+				p.next()
+			} else {
+				p.tokens[p.pos] = queryToken{tokenWord, tok.text[1:], tok.pos + 1}
+			}
+			node, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			if prefix == '-' {
+				excluded = append(excluded, node)
+			} else {
+				required = append(required, node)
+			}
+
+		default:
+			node, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := node.(*filterNode); ok {
+				required = append(required, node)
+				continue
+			}
+			plain = append(plain, node)
+		}
+	}
+
+	// plain words become optional once any word is explicitly required
+	if hasRequiredTerm(required) {
+		optional = plain
+	} else {
+		required = append(required, plain...)
+	}
+	if len(required)+len(optional)+len(excluded) == 0 {
+		tok := p.peek()
+		return nil, &QueryError{Pos: tok.pos, Message: "expected a search term"}
+	}
+	if len(required) == 1 && len(optional) == 0 && len(excluded) == 0 {
+		return required[0], nil
+	}
+	return &andNode{required: required, optional: optional, excluded: excluded}, nil
+}
+
+// isPrefixed reports whether tok is a term, phrase or group marked as required with + or
+// excluded with -.
+func isPrefixed(tok queryToken, next queryToken) bool {
+	if tok.kind != tokenWord || (tok.text[0] != '-' && tok.text[0] != '+') {
+		return false
+	}
+	if len(tok.text) > 1 {
+		return true
+	}
+	return (next.kind == tokenOpen || next.kind == tokenPhrase) && next.pos == tok.pos+1
+}
+
+func hasRequiredTerm(nodes []queryNode) bool {
+	for _, node := range nodes {
+		if _, ok := node.(*filterNode); !ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *queryParser) parsePrimary() (queryNode, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenOpen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenClose {
+			return nil, &QueryError{Pos: tok.pos, Message: "missing closing parenthesis"}
+		}
+		p.next()
+		return node, nil
+
+	case tokenPhrase:
+		return phraseOf(tok)
+
+	case tokenWord:
+		if tok.text == "AND" || tok.text == "OR" || tok.text == "NOT" {
+			return nil, &QueryError{Pos: tok.pos, Message: fmt.Sprintf("expected a search term before %s", tok.text)}
+		}
+		if nearOperator.MatchString(tok.text) {
+			return nil, &QueryError{Pos: tok.pos, Message: fmt.Sprintf("%s must be between two words", tok.text)}
+		}
+		if i := strings.IndexByte(tok.text, ':'); i > 0 && queryFilters[strings.ToLower(tok.text[:i])] {
+			return p.filterOf(tok, i)
+		}
+
+		word := strings.ToLower(tok.text)
+		if isWildcard(word) {
+			return wildcardOf(tok.pos, word)
+		}
+		near := p.peek()
+		m := nearOperator.FindStringSubmatch(near.text)
+		if near.kind != tokenWord || m == nil {
+			return &termNode{word: word}, nil
+		}
+		p.next()
+		other := p.next()
+		if other.kind != tokenWord || strings.ContainsAny(other.text, ":") {
+			return nil, &QueryError{Pos: near.pos, Message: fmt.Sprintf("%s must be between two words", near.text)}
+		}
+		distance, err := strconv.Atoi(m[1])
+		if err != nil || distance < 1 {
+			return nil, &QueryError{Pos: near.pos, Message: "NEAR distance must be a positive number"}
+		}
+		return &nearNode{a: word, b: strings.ToLower(other.text), distance: distance}, nil
+
+	case tokenClose:
+		return nil, &QueryError{Pos: tok.pos, Message: "unexpected closing parenthesis"}
+	}
+	return nil, &QueryError{Pos: tok.pos, Message: "unexpected end of query"}
+}
+
+func wildcardOf(pos int, pattern string) (queryNode, error) {
+	if strings.Trim(pattern, "*?") == "" {
+		return nil, &QueryError{Pos: pos, Message: "wildcard must include a letter"}
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, &QueryError{Pos: pos, Message: fmt.Sprintf("invalid wildcard: %v", err)}
+	}
+	return &wildcardNode{pattern: pattern}, nil
+}
+
+func phraseOf(tok queryToken) (queryNode, error) {
+	var words []string
+	tokenizeProse(tok.text, FieldBody, func(word string, _ Field) {
+		words = append(words, word)
+	})
+	if len(words) == 0 {
+		return nil, &QueryError{Pos: tok.pos, Message: "empty phrase"}
+	}
+	return &phraseNode{words: words}, nil
+}
+
+func (p *queryParser) filterOf(tok queryToken, colon int) (queryNode, error) {
+	field := strings.ToLower(tok.text[:colon])
+	value := strings.Trim(tok.text[colon+1:], `"`)
+	if value == "" {
+		return nil, &QueryError{Pos: tok.pos + colon + 1, Message: fmt.Sprintf("missing value for %s filter", field)}
+	}
+	if f, ok := queryFields[field]; ok {
+		var words []string
+		tokenizeProse(value, f, func(word string, _ Field) {
+			words = append(words, word)
+		})
+		if len(words) == 0 {
+			return nil, &QueryError{Pos: tok.pos + colon + 1, Message: fmt.Sprintf("no words in %s filter", field)}
+		}
+		return &fieldNode{field: f, words: words}, nil
+	}
+
+	if field == "sym" {
+		return &symbolNode{name: value}, nil
+	}
+
+	node := &filterNode{field: field, value: value}
+	switch field {
+	case "path":
+		_, err := path.Match(value, "")
+		if err != nil {
+			return nil, &QueryError{Pos: tok.pos + colon + 1, Message: fmt.Sprintf("invalid path pattern: %v", err)}
+		}
+	case "lang":
+		lang := strings.ToLower(value)
+		if !p.langs.Known(lang) {
+			return nil, &QueryError{Pos: tok.pos + colon + 1, Message: fmt.Sprintf("unknown language %q", value)}
+		}
+		node.re = p.langs.patterns[lang]
+	}
+	return node, nil
+}
+
+// hit is a matching document's relevance and the total edit distance of the words matched.
+type hit struct {
+	score    float64
+	distance int
+}
+
+type hits map[string]hit
+
+// evaluator executes a parsed query against an index.
+type evaluator struct {
+	index       *Index
+	opts        SearchOptions
+	all         []string
+	expansions  []Expansion
+	definitions []Definition
+	// terms holds the indexed words matched, excluded nodes are evaluated while negated
+	terms   map[string]bool
+	negated int
+}
+
+// Expansion records the indexed words a query term was matched against when they differ
+// from the term itself.
+type Expansion struct {
+	Term  string
+	Words Words
+}
+
+// expanded records the words matched for term unless only the term itself matched.
+func (e *evaluator) expanded(term string, words Words) {
+	for _, x := range e.expansions {
+		if x.Term == term {
+			return
+		}
+	}
+	e.expansions = append(e.expansions, Expansion{Term: term, Words: words})
+}
+
+// matched records the indexed words a term matched so they can be highlighted.
+func (e *evaluator) matched(words Words) {
+	if e.negated > 0 {
+		return
+	}
+	if e.terms == nil {
+		e.terms = make(map[string]bool)
+	}
+	for _, w := range words {
+		e.terms[w.Word] = true
+	}
+}
+
+// matchedForms records the analyzed forms of words typed in a phrase or filter.
+func (e *evaluator) matchedForms(words ...string) {
+	for _, word := range words {
+		for _, form := range append([]string{word}, e.index.Forms(word)...) {
+			e.matched(Words{{form, 0}})
+		}
+	}
+}
+
+// fuzzyMatch returns true if words were not matched exactly, as typed or analyzed.
+func fuzzyMatch(words Words) bool {
+	for _, w := range words {
+		if w.Distance > 0 {
+			return true
+		}
+	}
+	return len(words) == 0
+}
+
+// documents returns every document in the index, used by filters and negation.
+func (e *evaluator) documents() []string {
+	if e.all == nil {
+		e.all = e.index.Documents()
+	}
+	return e.all
+}
+
+func docListHits(docs DocList, err error) hits {
+	h := make(hits, len(docs))
+	if err != nil {
+		log.Printf("search=failed error='%v'\n", err)
+		return h
+	}
+	for _, d := range docs {
+		h[d.Document] = hit{score: d.Score * fuzzyPenalty(d.Distance), distance: d.Distance}
+	}
+	return h
+}
+
+func (n *termNode) eval(e *evaluator) hits {
+	if e.index.Ignored(n.word) {
+		// stop words are never indexed so they neither match nor exclude documents
+		return nil
+	}
+	docs, words, err := e.index.SearchWith(n.word, e.opts)
+	if fuzzyMatch(words) {
+		e.expanded(n.word, words)
+	}
+	e.matched(words)
+	return docListHits(docs, err)
+}
+
+func (n *wildcardNode) eval(e *evaluator) hits {
+	docs, words, err := e.index.Wildcard(n.pattern)
+	e.expanded(n.pattern, words)
+	e.matched(words)
+	return docListHits(docs, err)
+}
+
+func (n *phraseNode) eval(e *evaluator) hits {
+	e.matchedForms(n.words...)
+	return docListHits(e.index.Phrase(n.words))
+}
+
+func (n *nearNode) eval(e *evaluator) hits {
+	e.matchedForms(n.a, n.b)
+	return docListHits(e.index.Near(n.a, n.b, n.distance))
+}
+
+func (n *fieldNode) eval(e *evaluator) hits {
+	e.matchedForms(n.words...)
+	return docListHits(e.index.InField(n.field, n.words))
+}
+
+func (n *symbolNode) eval(e *evaluator) hits {
+	defs := e.index.Definitions(n.name)
+	e.definitions = append(e.definitions, defs...)
+	e.matchedForms(n.name[strings.LastIndexByte(n.name, '.')+1:])
+	h := make(hits)
+	for _, def := range defs {
+		cur := h[def.Document]
+		cur.score++
+		h[def.Document] = cur
+	}
+	return h
+}
+
+func (n *filterNode) eval(e *evaluator) hits {
+	h := make(hits)
+	for _, doc := range e.documents() {
+		if n.matches(e.index, doc) {
+			h[doc] = hit{}
+		}
+	}
+	return h
+}
+
+func (n *filterNode) matches(index *Index, doc string) bool {
+	switch n.field {
+	case "path":
+		if strings.ContainsAny(n.value, "*?[") {
+			ok, _ := path.Match(n.value, filepath.ToSlash(doc))
+			return ok
+		}
+		return strings.Contains(filepath.ToSlash(doc), n.value)
+	case "ext":
+		return strings.EqualFold(strings.TrimPrefix(filepath.Ext(doc), "."), strings.TrimPrefix(n.value, "."))
+	case "lang":
+		// documents indexed without a language are recognised by their extension
+		if lang := index.Language(doc); lang != "" {
+			return lang == canonicalLanguage(strings.ToLower(n.value))
+		}
+		return n.re.MatchString(doc)
+	}
+	return false
+}
+
+func (n *orNode) eval(e *evaluator) hits {
+	h := make(hits)
+	for _, node := range n.nodes {
+		for doc, r := range node.eval(e) {
+			cur := h[doc]
+			cur.score += r.score
+			if _, ok := h[doc]; !ok || r.distance < cur.distance {
+				cur.distance = r.distance
+			}
+			h[doc] = cur
+		}
+	}
+	return h
+}
+
+func (n *andNode) eval(e *evaluator) hits {
+	var h hits
+	if len(n.required) == 0 {
+		// a purely negative query excludes from every document
+		h = make(hits)
+		for _, doc := range e.documents() {
+			h[doc] = hit{}
+		}
+	}
+	for _, node := range n.required {
+		r := node.eval(e)
+		if r == nil {
+			continue
+		}
+		if h == nil {
+			h = r
+			continue
+		}
+		for doc, cur := range h {
+			other, ok := r[doc]
+			if !ok {
+				delete(h, doc)
+				continue
+			}
+			h[doc] = hit{score: cur.score + other.score, distance: cur.distance + other.distance}
+		}
+	}
+	for _, node := range n.optional {
+		for doc, other := range node.eval(e) {
+			if cur, ok := h[doc]; ok {
+				cur.score += other.score
+				h[doc] = cur
+			}
+		}
+	}
+	e.negated++
+	for _, node := range n.excluded {
+		for doc := range node.eval(e) {
+			delete(h, doc)
+		}
+	}
+	e.negated--
+	return h
+}
+
+// SearchResult is the outcome of executing a query.
+type SearchResult struct {
+	Docs ScoreList
+	// Expansions lists the query terms which were matched against other words.
+	Expansions []Expansion
+	// Definitions locates the symbols of sym: filters in the matching documents.
+	Definitions []Definition
+	// Terms are the indexed words the query matched, sorted.
+	Terms []string
+}
+
+// SearchWith executes the query against the index matching its terms as opts specify.
+func SearchWith(query string, index *Index, opts SearchOptions) (*SearchResult, error) {
+	node, err := parseQuery(query, index.Registry())
+	if err != nil {
+		return nil, err
+	}
+	if node == nil {
+		return &SearchResult{Docs: ScoreList{}}, nil
+	}
+
+	words := strings.Join(plainWords(node), " ")
+	e := &evaluator{index: index, opts: opts}
+	result := node.eval(e)
+	list := make(ScoreList, 0, len(result))
+	for n, h := range result {
+		list = append(list, Score{
+			Document:       n,
+			Score:          h.score,
+			Distance:       h.distance,
+			NameSimilarity: nameSimilarity(words, n),
+		})
+	}
+	// equally relevant documents are ordered by how well their name matches
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].NameSimilarity != list[j].NameSimilarity {
+			return list[i].NameSimilarity > list[j].NameSimilarity
+		}
+		return list[i].Document < list[j].Document
+	})
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Score > list[j].Score
+	})
+
+	var defs []Definition
+	for _, def := range e.definitions {
+		if _, ok := result[def.Document]; ok {
+			defs = append(defs, def)
+		}
+	}
+	terms := make([]string, 0, len(e.terms))
+	for term := range e.terms {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	return &SearchResult{Docs: list, Expansions: e.expansions, Definitions: defs, Terms: terms}, nil
+}
+
+// plainWords returns the words of the terms node searches for, leaving out filters,
+// operators and anything excluded.
+func plainWords(node queryNode) []string {
+	var words []string
+	switch n := node.(type) {
+	case *termNode:
+		words = append(words, n.word)
+	case *orNode:
+		for _, child := range n.nodes {
+			words = append(words, plainWords(child)...)
+		}
+	case *andNode:
+		for _, child := range n.required {
+			words = append(words, plainWords(child)...)
+		}
+		for _, child := range n.optional {
+			words = append(words, plainWords(child)...)
+		}
+	}
+	return words
+}
+
+// nameSimilarity compares the query words to the file name of doc without its extension.
+func nameSimilarity(query string, doc string) float64 {
+	name := strings.ToLower(filepath.Base(doc))
+	name = strings.TrimSuffix(name, filepath.Ext(name))
+	return edit.JaroWinkler(query, name)
+}
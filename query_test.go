@@ -0,0 +1,222 @@
+package main
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func queryIndex() *Index {
+	index := New(4)
+	docs := map[string]string{
+		"docs/maven.md":  "Migrating from maven to bazel",
+		"docs/gradle.md": "Migrating from gradle to bazel",
+		"notes/maven.md": "maven release notes",
+		"src/Build.java": "maven gradle",
+	}
+	for name, text := range docs {
+		doc := markdownFrequency(text, nil)
+		doc.Name = name
+		index.Update(doc)
+	}
+	return index
+}
+
+func documents(scores ScoreList) []string {
+	var names []string
+	for _, s := range scores {
+		names = append(names, s.Document)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func Test_search_query_language(t *testing.T) {
+	cases := map[string][]string{
+		`maven`:                         {"docs/maven.md", "notes/maven.md", "src/Build.java"},
+		`maven bazel`:                   {"docs/maven.md"},
+		`maven AND bazel`:               {"docs/maven.md"},
+		`maven OR gradle`:               {"docs/gradle.md", "docs/maven.md", "notes/maven.md", "src/Build.java"},
+		`bazel OR release notes`:        {"docs/gradle.md", "docs/maven.md", "notes/maven.md"},
+		`maven -bazel`:                  {"notes/maven.md", "src/Build.java"},
+		`maven NOT bazel`:               {"notes/maven.md", "src/Build.java"},
+		`NOT maven`:                     {"docs/gradle.md"},
+		`(maven OR gradle) bazel`:       {"docs/gradle.md", "docs/maven.md"},
+		`maven -(bazel OR release)`:     {"src/Build.java"},
+		`+bazel maven`:                  {"docs/gradle.md", "docs/maven.md"},
+		`"from maven to"`:               {"docs/maven.md"},
+		`bazel -"maven to bazel"`:       {"docs/gradle.md"},
+		`-"maven to bazel"`:             {"docs/gradle.md", "notes/maven.md", "src/Build.java"},
+		`migrating +"gradle to"`:        {"docs/gradle.md"},
+		`migrating NEAR/4 bazel`:        {"docs/gradle.md", "docs/maven.md"},
+		`maven path:docs/`:              {"docs/maven.md"},
+		`maven path:*/maven.md`:         {"docs/maven.md", "notes/maven.md"},
+		`maven ext:java`:                {"src/Build.java"},
+		`mav*`:                          {"docs/maven.md", "notes/maven.md", "src/Build.java"},
+		`*adle -bazel`:                  {"src/Build.java"},
+		`maven lang:english`:            {"docs/maven.md", "notes/maven.md"},
+		`path:notes OR lang:java`:       {"notes/maven.md", "src/Build.java"},
+		`maven -path:"notes"`:           {"docs/maven.md", "src/Build.java"},
+		`gradle OR maven AND release`:   {"docs/gradle.md", "notes/maven.md", "src/Build.java"},
+		`(gradle OR maven) AND release`: {"notes/maven.md"},
+	}
+	index := queryIndex()
+	for query, expected := range cases {
+		scores, err := Search(query, index)
+		if err != nil {
+			t.Errorf("Search(%q) error=%v, want nil", query, err)
+			continue
+		}
+		actual := documents(scores)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("Search(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, actual))
+		}
+	}
+}
+
+func Test_optional_terms_rank_higher(t *testing.T) {
+	index := queryIndex()
+	scores, _ := Search("+maven release", index)
+	if len(scores) != 3 || scores[0].Document != "notes/maven.md" {
+		t.Errorf("Search(`+maven release`)=%v, want notes/maven.md first of 3", scores)
+	}
+}
+
+func Test_parse_query_errors(t *testing.T) {
+	cases := map[string]QueryError{
+		`maven OR`:           {Pos: 8, Message: "expected a search term"},
+		`(maven bazel`:       {Pos: 0, Message: "missing closing parenthesis"},
+		`maven)`:             {Pos: 5, Message: `unexpected ")"`},
+		`"maven to`:          {Pos: 0, Message: "unterminated phrase"},
+		`maven NEAR/3`:       {Pos: 6, Message: "NEAR/3 must be between two words"},
+		`maven NEAR/0 bazel`: {Pos: 6, Message: "NEAR distance must be a positive number"},
+		`ext:`:               {Pos: 4, Message: "missing value for ext filter"},
+		`lang:cobol`:         {Pos: 5, Message: `unknown language "cobol"`},
+		`path:[`:             {Pos: 5, Message: "invalid path pattern: syntax error in pattern"},
+		`AND maven`:          {Pos: 0, Message: "expected a search term before AND"},
+		`maven *`:            {Pos: 6, Message: "wildcard must include a letter"},
+		`mav[en`:             {Pos: 0, Message: "invalid wildcard: syntax error in pattern"},
+		`comment:"!"`:        {Pos: 8, Message: "no words in comment filter"},
+	}
+	for query, expected := range cases {
+		_, err := ParseQuery(query)
+		actual, ok := err.(*QueryError)
+		if !ok {
+			t.Errorf("ParseQuery(%q) error=%v, want QueryError", query, err)
+			continue
+		}
+		if !cmp.Equal(*actual, expected) {
+			t.Errorf("ParseQuery(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, *actual))
+		}
+	}
+}
+
+func Test_search_options_max_distance(t *testing.T) {
+	cases := map[string]struct {
+		opts     SearchOptions
+		word     string
+		expected int
+	}{
+		"auto short":  {DefaultSearchOptions, "go", 0},
+		"auto medium": {DefaultSearchOptions, "maven", 2},
+		"auto long":   {DefaultSearchOptions, "kubernetes", 4},
+		"fixed":       {SearchOptions{MaxDistance: 6}, "go", 6},
+		"exact":       {SearchOptions{MaxDistance: 6, Exact: true}, "kubernetes", 0},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			actual := tc.opts.maxDistance(tc.word)
+			if actual != tc.expected {
+				t.Errorf("maxDistance(%q)=%d, want %d", tc.word, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_search_with_options(t *testing.T) {
+	index := New(3)
+	index.Update(&Document{Name: "a.md", WordCount: map[string]int{"docker": 1}})
+	index.Update(&Document{Name: "b.md", WordCount: map[string]int{"dockers": 1}})
+	index.Update(&Document{Name: "c.md", WordCount: map[string]int{"kubernetes": 1}})
+
+	cases := map[string]struct {
+		query      string
+		opts       SearchOptions
+		docs       []string
+		expansions []Expansion
+	}{
+		"exact hit":      {"docker", DefaultSearchOptions, []string{"a.md"}, nil},
+		"typo":           {"dokcer", DefaultSearchOptions, []string{"a.md"}, []Expansion{{"dokcer", Words{{"docker", 2}}}}},
+		"nonsense":       {"zzzzzz", DefaultSearchOptions, nil, []Expansion{{"zzzzzz", Words{}}}},
+		"always expand":  {"docker", SearchOptions{MaxDistance: AutoDistance, AlwaysExpand: true}, []string{"a.md", "b.md"}, []Expansion{{"docker", Words{{"docker", 0}, {"dockers", 1}}}}},
+		"exact disabled": {"dokcer", SearchOptions{Exact: true}, nil, []Expansion{{"dokcer", nil}}},
+		"fixed distance": {"kubern", SearchOptions{MaxDistance: 4}, []string{"c.md"}, []Expansion{{"kubern", Words{{"kubernetes", 4}}}}},
+		"wildcard":       {"dock*", DefaultSearchOptions, []string{"a.md", "b.md"}, []Expansion{{"dock*", Words{{"docker", 0}, {"dockers", 0}}}}},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			result, err := SearchWith(tc.query, index, tc.opts)
+			if err != nil {
+				t.Fatalf("SearchWith(%q) error=%v, want nil", tc.query, err)
+			}
+			docs := documents(result.Docs)
+			if !cmp.Equal(docs, tc.docs) {
+				t.Errorf("SearchWith(%q) docs mismatch (-want +got)\n%s", tc.query, cmp.Diff(tc.docs, docs))
+			}
+			if !cmp.Equal(result.Expansions, tc.expansions, cmpopts.EquateEmpty()) {
+				t.Errorf("SearchWith(%q) expansions mismatch (-want +got)\n%s", tc.query, cmp.Diff(tc.expansions, result.Expansions))
+			}
+		})
+	}
+}
+
+func Test_equal_scores_rank_by_name_similarity(t *testing.T) {
+	index := New(3)
+	for _, name := range []string{"a/misc.md", "b/dockerfile.md", "c/docker.md", "d/docker-compose.md"} {
+		index.Update(&Document{Name: name, WordCount: map[string]int{"docker": 1}})
+	}
+
+	cases := map[string][]string{
+		"docker":                          {"c/docker.md", "b/dockerfile.md", "d/docker-compose.md", "a/misc.md"},
+		"docker path:*/*.md":              {"c/docker.md", "b/dockerfile.md", "d/docker-compose.md", "a/misc.md"},
+		"docker -compose ext:md -path:a/": {"c/docker.md", "b/dockerfile.md", "d/docker-compose.md"},
+	}
+	for query, expected := range cases {
+		scores, err := Search(query, index)
+		if err != nil {
+			t.Errorf("Search(%q) error=%v, want nil", query, err)
+			continue
+		}
+		var actual []string
+		for _, s := range scores {
+			actual = append(actual, s.Document)
+		}
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("Search(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, actual))
+		}
+	}
+}
+
+func Test_search_result_terms(t *testing.T) {
+	index := queryIndex()
+	cases := map[string][]string{
+		"maven":                  {"maven"},
+		"maven -gradle":          {"maven"},
+		`"to bazel" OR mavne`:    {"bazel", "maven", "to"},
+		"migrat* path:docs/*.md": {"migrating"},
+	}
+	for query, expected := range cases {
+		result, err := SearchWith(query, index, DefaultSearchOptions)
+		if err != nil {
+			t.Errorf("SearchWith(%q) error=%v, want nil", query, err)
+			continue
+		}
+		if !cmp.Equal(result.Terms, expected) {
+			t.Errorf("SearchWith(%q) terms mismatch (-want +got)\n%s", query, cmp.Diff(expected, result.Terms))
+		}
+	}
+}
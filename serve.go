@@ -1,12 +1,20 @@
 package main
 
 import (
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"github.com/rakyll/statik/fs"
+	"hash/fnv"
 	"log"
 	"mime"
 	"net/http"
+	"net/url"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/nfisher/mdindexer/statik"
 )
@@ -20,7 +28,7 @@ const (
 	ApplicationJs     = `application/javascript; charset=utf-8`
 )
 
-func BuildRoutes(paths []string, index *Index) *http.ServeMux {
+func BuildRoutes(ix *Indexer, index *Index) *http.ServeMux {
 	mime.AddExtensionType(".js", ApplicationJs)
 	mux := http.NewServeMux()
 
@@ -30,26 +38,267 @@ func BuildRoutes(paths []string, index *Index) *http.ServeMux {
 	}
 	mux.Handle("/", http.FileServer(files))
 
-	for _, p := range paths {
+	for _, p := range ix.Corpora.Paths() {
 		prefix := filepath.Join("/files", p)
 		mux.Handle(prefix+"/", http.StripPrefix(prefix, http.FileServer(http.Dir(p))))
 	}
 
 	mux.HandleFunc("/search", SearchIndex(index))
+	mux.HandleFunc("/complete", CompleteWord(index))
+	mux.HandleFunc("/symbols", FindSymbols(index))
+	mux.HandleFunc("/discovery", DiscoveryReport(ix))
 
 	return mux
 }
 
 type SearchResponse struct {
-	Docs ScoreList
+	Docs []Hit
+	// Total is the number of documents matching the query.
+	Total  int
+	Offset int
+	// Next is the cursor of the following page, empty on the last page.
+	Next string `json:",omitempty"`
+	// TookMs is how long the query and snippets took in milliseconds.
+	TookMs      float64
+	Expansions  []Expansion  `json:",omitempty"`
+	Definitions []Definition `json:",omitempty"`
+	Error       *QueryError  `json:",omitempty"`
 }
 
+// Hit is a matching document with the lines showing why it matched.
+type Hit struct {
+	Score
+	Snippets []Snippet `json:",omitempty"`
+}
+
+const (
+	// defaultHits is the number of documents returned by /search without a limit.
+	defaultHits = 20
+	maxHits     = 100
+	// snippetsPerHit is the number of lines shown for each document.
+	snippetsPerHit = 3
+)
+
+// page selects the documents of a search response.
+type page struct {
+	offset   int
+	limit    int
+	snippets bool
+}
+
+// searchPage reads the limit, offset, cursor and snippets parameters. A cursor continues
+// the query it was returned for and replaces offset.
+func searchPage(params url.Values) (page, error) {
+	p := page{limit: defaultHits, snippets: true}
+	var err error
+	if v := params.Get("limit"); v != "" {
+		p.limit, err = strconv.Atoi(v)
+		if err != nil || p.limit < 1 || p.limit > maxHits {
+			return p, &QueryError{Param: "limit", Message: fmt.Sprintf("limit must be from 1 to %d", maxHits)}
+		}
+	}
+	if v := params.Get("offset"); v != "" {
+		p.offset, err = strconv.Atoi(v)
+		if err != nil || p.offset < 0 {
+			return p, &QueryError{Param: "offset", Message: "offset must be 0 or more"}
+		}
+	}
+	if v := params.Get("cursor"); v != "" {
+		p.offset, err = decodeCursor(v, params.Get("q"))
+		if err != nil {
+			return p, &QueryError{Param: "cursor", Message: err.Error()}
+		}
+	}
+	if v := params.Get("snippets"); v != "" {
+		p.snippets, err = strconv.ParseBool(v)
+		if err != nil {
+			return p, &QueryError{Param: "snippets", Message: "snippets must be true or false"}
+		}
+	}
+	return p, nil
+}
+
+// encodeCursor returns an opaque cursor for the documents of query from offset.
+func encodeCursor(offset int, query string) string {
+	h := fnv.New32a()
+	h.Write([]byte(query))
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%x", offset, h.Sum32())))
+}
+
+func decodeCursor(cursor string, query string) (int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("cursor is invalid")
+	}
+	i := strings.IndexByte(string(b), ':')
+	if i < 0 {
+		return 0, fmt.Errorf("cursor is invalid")
+	}
+	offset, err := strconv.Atoi(string(b[:i]))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("cursor is invalid")
+	}
+	if encodeCursor(offset, query) != cursor {
+		return 0, fmt.Errorf("cursor belongs to another query")
+	}
+	return offset, nil
+}
+
+// searchOptions reads the fuzzy, exact and expand parameters.
+func searchOptions(params url.Values) (SearchOptions, error) {
+	opts := DefaultSearchOptions
+	var err error
+	if v := params.Get("fuzzy"); v != "" {
+		opts.MaxDistance, err = strconv.Atoi(v)
+		if err != nil || opts.MaxDistance < 0 {
+			return opts, &QueryError{Param: "fuzzy", Message: "fuzzy must be a distance of 0 or more"}
+		}
+	}
+	if v := params.Get("exact"); v != "" {
+		opts.Exact, err = strconv.ParseBool(v)
+		if err != nil {
+			return opts, &QueryError{Param: "exact", Message: "exact must be true or false"}
+		}
+	}
+	if v := params.Get("expand"); v != "" {
+		opts.AlwaysExpand, err = strconv.ParseBool(v)
+		if err != nil {
+			return opts, &QueryError{Param: "expand", Message: "expand must be true or false"}
+		}
+	}
+	return opts, nil
+}
+
+// SearchIndex returns a page of the documents matching the q parameter with snippets of
+// the lines they matched on.
 func SearchIndex(index *Index) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
-		needle := r.URL.Query().Get("q")
-		docs := Search(needle, index)
+		ts := time.Now()
+		params := r.URL.Query()
+		// invalid parameters are reported in the same way as query errors
+		opts, err := searchOptions(params)
+		var pg page
+		if err == nil {
+			pg, err = searchPage(params)
+		}
+		var result *SearchResult
+		if err == nil {
+			result, err = SearchWith(params.Get("q"), index, opts)
+		}
+		var qerr *QueryError
+		if err != nil && !errors.As(err, &qerr) {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ApplicationJson)
+		resp := &SearchResponse{Docs: []Hit{}, Offset: pg.offset, Error: qerr}
+		if qerr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			resp.Total = len(result.Docs)
+			resp.Docs = pageHits(index, result, pg)
+			if end := pg.offset + len(resp.Docs); end < resp.Total {
+				resp.Next = encodeCursor(end, params.Get("q"))
+			}
+			resp.Expansions = result.Expansions
+			resp.Definitions = result.Definitions
+		}
+		resp.TookMs = float64(time.Since(ts).Microseconds()) / 1000
+		err = json.NewEncoder(w).Encode(resp)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// pageHits returns the page of documents in result with their snippets.
+func pageHits(index *Index, result *SearchResult, pg page) []Hit {
+	list := []Hit{}
+	if pg.offset >= len(result.Docs) {
+		return list
+	}
+	docs := result.Docs[pg.offset:]
+	if len(docs) > pg.limit {
+		docs = docs[:pg.limit]
+	}
+	for _, doc := range docs {
+		hit := Hit{Score: doc}
+		if pg.snippets {
+			snippets, err := index.Snippets(doc.Document, result.Terms, snippetsPerHit)
+			if err != nil {
+				log.Printf("snippets=failed filename=%s error='%v'\n", doc.Document, err)
+			}
+			hit.Snippets = snippets
+		}
+		list = append(list, hit)
+	}
+	return list
+}
+
+const (
+	// defaultCompletions is the number of words returned by /complete without a limit.
+	defaultCompletions = 10
+	maxCompletions     = 100
+)
+
+type CompleteResponse struct {
+	Words []string
+}
+
+// CompleteWord suggests indexed words starting with the q parameter.
+func CompleteWord(index *Index) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		prefix := strings.ToLower(r.URL.Query().Get("q"))
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		if err != nil || limit < 1 {
+			limit = defaultCompletions
+		}
+		if limit > maxCompletions {
+			limit = maxCompletions
+		}
+
+		words := []string{}
+		if prefix != "" {
+			words = append(words, index.Complete(prefix, limit)...)
+		}
+		w.Header().Set(HeaderContentType, ApplicationJson)
+		err = json.NewEncoder(w).Encode(&CompleteResponse{Words: words})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+type SymbolsResponse struct {
+	Definitions []Definition
+}
+
+// FindSymbols locates the declarations of the symbol named by the q parameter.
+func FindSymbols(index *Index) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("q")
+		if name == "" {
+			http.Error(w, "q must name a symbol", http.StatusBadRequest)
+			return
+		}
+
+		defs := []Definition{}
+		defs = append(defs, index.Definitions(name)...)
+		w.Header().Set(HeaderContentType, ApplicationJson)
+		err := json.NewEncoder(w).Encode(&SymbolsResponse{Definitions: defs})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
+
+// DiscoveryReport lists the paths skipped by the last walk of the corpora.
+func DiscoveryReport(ix *Indexer) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := ix.Discovery()
 		w.Header().Set(HeaderContentType, ApplicationJson)
-		err := json.NewEncoder(w).Encode(&SearchResponse{Docs: docs})
+		err := json.NewEncoder(w).Encode(&report)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 		}
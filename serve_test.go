@@ -1,22 +1,31 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 )
 
+func testIndexer() *Indexer {
+	return &Indexer{Corpora: Corpora{NewCorpus("testdata", Languages{"english"})}, Languages: Languages{"english"}, Workers: 2}
+}
+
 func Test_routes_ok(t *testing.T) {
 	cases := map[string]struct {
 		method      string
 		path        string
 		contentType string
 	}{
-		"search":  {http.MethodGet, "/search?q=development", ApplicationJson},
-		"file":    {http.MethodGet, "/files/testdata/hello.html", TextHtml},
-		"root":    {http.MethodGet, "/", TextHtml},
-		"main.js": {http.MethodGet, "/main.js", ApplicationJs},
+		"search":    {http.MethodGet, "/search?q=development", ApplicationJson},
+		"complete":  {http.MethodGet, "/complete?q=dev", ApplicationJson},
+		"symbols":   {http.MethodGet, "/symbols?q=New", ApplicationJson},
+		"discovery": {http.MethodGet, "/discovery", ApplicationJson},
+		"file":      {http.MethodGet, "/files/testdata/hello.html", TextHtml},
+		"root":      {http.MethodGet, "/", TextHtml},
+		"main.js":   {http.MethodGet, "/main.js", ApplicationJs},
 	}
 	index := New(10)
 	index.Update(&Document{Name: "index.md", WordCount: map[string]int{"development": 1}})
@@ -30,7 +39,7 @@ func Test_routes_ok(t *testing.T) {
 				t.Errorf("NewRequest(%s, %s, ...) error=%v, want nil", tc.method, url, err)
 			}
 
-			mux := BuildRoutes([]string{"testdata"}, index)
+			mux := BuildRoutes(testIndexer(), index)
 			mux.ServeHTTP(w, r)
 			if w.Code != http.StatusOK {
 				t.Errorf("w.Code=%d, want 200", w.Code)
@@ -42,3 +51,113 @@ func Test_routes_ok(t *testing.T) {
 		})
 	}
 }
+
+func Test_search_reports_query_errors(t *testing.T) {
+	index := New(10)
+	index.Update(&Document{Name: "index.md", WordCount: map[string]int{"development": 1}})
+	w := httptest.NewRecorder()
+	r, err := http.NewRequest(http.MethodGet, "http://localhost/search?q=development+OR", nil)
+	if err != nil {
+		t.Fatalf("NewRequest() error=%v, want nil", err)
+	}
+
+	BuildRoutes(testIndexer(), index).ServeHTTP(w, r)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("w.Code=%d, want 400", w.Code)
+	}
+	var resp SearchResponse
+	err = json.NewDecoder(w.Body).Decode(&resp)
+	if err != nil {
+		t.Fatalf("Decode() error=%v, want nil", err)
+	}
+	if resp.Error == nil || resp.Error.Pos != 14 {
+		t.Errorf("resp.Error=%v, want position 14", resp.Error)
+	}
+}
+
+func Test_search_options_from_parameters(t *testing.T) {
+	cases := map[string]struct {
+		query    string
+		expected SearchOptions
+		ok       bool
+	}{
+		"default":   {"q=a", DefaultSearchOptions, true},
+		"fuzzy":     {"q=a&fuzzy=2", SearchOptions{MaxDistance: 2}, true},
+		"exact":     {"q=a&exact=true", SearchOptions{MaxDistance: AutoDistance, Exact: true}, true},
+		"expand":    {"q=a&expand=1", SearchOptions{MaxDistance: AutoDistance, AlwaysExpand: true}, true},
+		"negative":  {"q=a&fuzzy=-1", SearchOptions{}, false},
+		"not a num": {"q=a&fuzzy=lots", SearchOptions{}, false},
+		"bad bool":  {"q=a&exact=yes", SearchOptions{}, false},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			params, _ := url.ParseQuery(tc.query)
+			actual, err := searchOptions(params)
+			if (err == nil) != tc.ok {
+				t.Fatalf("searchOptions(%q) error=%v, want ok=%v", tc.query, err, tc.ok)
+			}
+			if tc.ok && actual != tc.expected {
+				t.Errorf("searchOptions(%q)=%+v, want %+v", tc.query, actual, tc.expected)
+			}
+		})
+	}
+}
+
+func Test_search_pages_results_with_snippets(t *testing.T) {
+	ix := testIndexer()
+	index := ix.Build()
+	index.SetAnalyzers(ix.registry().Analyzers(ix.Languages)...)
+	mux := BuildRoutes(ix, index)
+	search := func(query string) (int, *SearchResponse) {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest(http.MethodGet, "http://localhost/search?"+query, nil)
+		if err != nil {
+			t.Fatalf("NewRequest() error=%v, want nil", err)
+		}
+		mux.ServeHTTP(w, r)
+		var resp SearchResponse
+		err = json.NewDecoder(w.Body).Decode(&resp)
+		if err != nil {
+			t.Fatalf("Decode() error=%v, want nil", err)
+		}
+		return w.Code, &resp
+	}
+
+	code, first := search("q=build&limit=1")
+	if code != http.StatusOK || first.Total != 2 || len(first.Docs) != 1 || first.Next == "" {
+		t.Fatalf("search(limit=1) code=%d total=%d docs=%d next=%q, want 200, 2, 1 and a cursor",
+			code, first.Total, len(first.Docs), first.Next)
+	}
+	if len(first.Docs[0].Snippets) == 0 || len(first.Docs[0].Snippets[0].Highlights) == 0 {
+		t.Errorf("Docs[0].Snippets=%v, want highlighted lines", first.Docs[0].Snippets)
+	}
+
+	code, second := search("q=build&limit=1&cursor=" + first.Next)
+	if code != http.StatusOK || second.Offset != 1 || len(second.Docs) != 1 || second.Next != "" {
+		t.Fatalf("search(cursor) code=%d offset=%d docs=%d next=%q, want 200, 1, 1 and no cursor",
+			code, second.Offset, len(second.Docs), second.Next)
+	}
+	if second.Docs[0].Document == first.Docs[0].Document {
+		t.Errorf("search(cursor) returned %s again", second.Docs[0].Document)
+	}
+
+	code, past := search("q=build&offset=5&snippets=false")
+	if code != http.StatusOK || past.Total != 2 || len(past.Docs) != 0 {
+		t.Errorf("search(offset=5) code=%d total=%d docs=%d, want 200, 2 and 0", code, past.Total, len(past.Docs))
+	}
+
+	invalid := map[string]string{
+		"q=build&limit=0":              "limit",
+		"q=build&offset=-1":            "offset",
+		"q=maven&cursor=" + first.Next: "cursor",
+		"q=build&cursor=x":             "cursor",
+		"q=build&fuzzy=lots":           "fuzzy",
+	}
+	for query, param := range invalid {
+		code, resp := search(query)
+		if code != http.StatusBadRequest || resp.Error == nil || resp.Error.Param != param {
+			t.Errorf("search(%s) code=%d error=%+v, want 400 and an error in %s", query, code, resp.Error, param)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	readTimeout  = 10 * time.Second
+	writeTimeout = 30 * time.Second
+	idleTimeout  = 2 * time.Minute
+	// shutdownTimeout bounds how long in flight requests are given to complete on exit.
+	shutdownTimeout = 10 * time.Second
+	// certCheckInterval is how often the certificate files are checked for changes.
+	certCheckInterval = 10 * time.Second
+	// unixPrefix marks an address as the path of a Unix domain socket.
+	unixPrefix = "unix:"
+)
+
+// newServer returns a server for handler with timeouts, serving TLS when the config names a
+// certificate.
+func newServer(cfg *Config, handler http.Handler) (*http.Server, error) {
+	srv := &http.Server{
+		Handler:      handler,
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+		IdleTimeout:  idleTimeout,
+	}
+	if cfg.TLS.CertFile != "" {
+		certs, err := newCertReloader(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: certs.GetCertificate,
+		}
+	}
+	return srv, nil
+}
+
+// listen opens addr which is either host:port or unix: followed by the path of a socket. A
+// socket left behind by an instance which exited uncleanly is replaced.
+func listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, unixPrefix) {
+		return net.Listen("tcp", addr)
+	}
+	path := strings.TrimPrefix(addr, unixPrefix)
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		conn, err := net.Dial("unix", path)
+		if err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("%s is in use", path)
+		}
+		err = os.Remove(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return net.Listen("unix", path)
+}
+
+// serve handles connections from ln until a value is received from stop, then waits for
+// in flight requests to complete. It returns nil after a graceful shutdown.
+func serve(srv *http.Server, ln net.Listener, stop <-chan os.Signal) error {
+	done := make(chan error, 1)
+	go func() {
+		sig := <-stop
+		log.Printf("shutdown=started signal=%v\n", sig)
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		done <- srv.Shutdown(ctx)
+	}()
+
+	var err error
+	if srv.TLSConfig != nil {
+		err = srv.ServeTLS(ln, "", "")
+	} else {
+		err = srv.Serve(ln)
+	}
+	if err != http.ErrServerClosed {
+		return err
+	}
+	return <-done
+}
+
+// certReloader serves a certificate and key read from files, reading them again when
+// either file is modified so renewed certificates are used without a restart.
+type certReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu      sync.Mutex
+	cert    *tls.Certificate
+	modTime time.Time
+	checked time.Time
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: certCheckInterval}
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return nil, err
+	}
+	err = r.load(modTime)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// latestModTime returns the latest modification time of the certificate and key.
+func (r *certReloader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, name := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(name)
+		if err != nil {
+			return latest, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+func (r *certReloader) load(modTime time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	r.cert = &cert
+	r.modTime = modTime
+	return nil
+}
+
+// GetCertificate returns the current certificate, a certificate which fails to load is
+// logged and the previous one kept.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	if now.Sub(r.checked) < r.interval {
+		return r.cert, nil
+	}
+	r.checked = now
+
+	modTime, err := r.latestModTime()
+	if err == nil && modTime.After(r.modTime) {
+		err = r.load(modTime)
+		if err == nil {
+			log.Printf("tls=reloaded cert=%s\n", r.certFile)
+		}
+	}
+	if err != nil {
+		log.Printf("tls=failed cert=%s error='%v'\n", r.certFile, err)
+	}
+	return r.cert, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func Test_serve_unix_socket_until_stopped(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "mdindexer.sock")
+
+	ln, err := listen(unixPrefix + socket)
+	if err != nil {
+		t.Skipf("unix sockets unavailable: %v", err)
+	}
+	srv, err := newServer(DefaultConfig(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan os.Signal, 1)
+	served := make(chan error, 1)
+	go func() {
+		served <- serve(srv, ln, stop)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		},
+	}}
+	resp, err := client.Get("http://mdindexer/")
+	if err != nil {
+		t.Fatalf("Get() error=%v, want nil", err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "hello" {
+		t.Errorf("Get() body=%q, want hello", body)
+	}
+
+	_, err = listen(unixPrefix + socket)
+	if err == nil || !strings.Contains(err.Error(), "is in use") {
+		t.Errorf("listen() error=%v, want in use", err)
+	}
+
+	stop <- syscall.SIGTERM
+	select {
+	case err = <-served:
+		if err != nil {
+			t.Errorf("serve() error=%v, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("serve() did not return after stop")
+	}
+	if _, err = os.Stat(socket); !os.IsNotExist(err) {
+		t.Errorf("Stat(socket) error=%v, want socket removed", err)
+	}
+}
+
+func writeCert(t *testing.T, certFile, keyFile string, name string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_cert_reloader_reads_changed_files(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	writeCert(t, certFile, keyFile, "first")
+
+	r, err := newCertReloader(certFile, keyFile)
+	if err != nil {
+		t.Fatalf("newCertReloader() error=%v, want nil", err)
+	}
+	r.interval = 0
+	first, _ := r.GetCertificate(nil)
+
+	writeCert(t, certFile, keyFile, "second")
+	later := time.Now().Add(time.Minute)
+	for _, name := range []string{certFile, keyFile} {
+		err = os.Chtimes(name, later, later)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	second, _ := r.GetCertificate(nil)
+	if bytes.Equal(first.Certificate[0], second.Certificate[0]) {
+		t.Errorf("GetCertificate() returned the first certificate after the files changed")
+	}
+
+	err = ioutil.WriteFile(keyFile, []byte("broken"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	latest := later.Add(time.Minute)
+	err = os.Chtimes(keyFile, latest, latest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kept, _ := r.GetCertificate(nil)
+	if kept != second {
+		t.Errorf("GetCertificate() replaced the certificate with one which failed to load")
+	}
+}
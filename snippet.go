@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxSnippetLen is the longest snippet in bytes, longer lines are cut around the first
+	// highlight.
+	maxSnippetLen = 200
+	// snippetLead is how much of a cut line is kept before its first highlight.
+	snippetLead = 40
+)
+
+// Snippet is a line of a document containing words the query matched.
+type Snippet struct {
+	// Line is numbered from 1.
+	Line int
+	Text string
+	// Highlights are the byte offsets in Text of each matched word as start and end pairs.
+	Highlights [][2]int
+}
+
+// Snippets returns up to max lines of filename containing the indexed words in terms,
+// preferring the lines which match the most distinct terms, in line order.
+func (z *Index) Snippets(filename string, terms []string, max int) ([]Snippet, error) {
+	if len(terms) == 0 || max < 1 {
+		return nil, nil
+	}
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	set := make(map[string]bool, len(terms))
+	for _, term := range terms {
+		set[term] = true
+	}
+	type candidate struct {
+		snippet  Snippet
+		distinct int
+	}
+	var candidates []candidate
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), sniffLen*64)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		highlights, distinct := z.highlight(text, set)
+		if len(highlights) == 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			snippet:  cutSnippet(Snippet{Line: line, Text: text, Highlights: highlights}),
+			distinct: distinct,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distinct > candidates[j].distinct
+	})
+	if len(candidates) > max {
+		candidates = candidates[:max]
+	}
+	snippets := make([]Snippet, 0, len(candidates))
+	for _, c := range candidates {
+		snippets = append(snippets, c.snippet)
+	}
+	sort.Slice(snippets, func(i, j int) bool {
+		return snippets[i].Line < snippets[j].Line
+	})
+	return snippets, nil
+}
+
+// highlight returns the offsets of the words in text whose analyzed form, or that of one of
+// their identifier parts, is in terms and the number of distinct terms found. The lock is
+// held for a line at a time so the file is not read while holding it.
+func (z *Index) highlight(text string, terms map[string]bool) ([][2]int, int) {
+	z.RLock()
+	defer z.RUnlock()
+	var highlights [][2]int
+	found := make(map[string]bool)
+	match := func(word string) bool {
+		var ok bool
+		word = strings.ToLower(word)
+		for _, form := range append([]string{word}, z.forms(word)...) {
+			if terms[form] {
+				found[form] = true
+				ok = true
+			}
+		}
+		return ok
+	}
+
+	start := -1
+	for i, r := range text + " " {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start < 0 {
+			continue
+		}
+		word := text[start:i]
+		ok := match(word)
+		for _, part := range splitIdent(word) {
+			if part != word && match(part) {
+				ok = true
+			}
+		}
+		if ok {
+			highlights = append(highlights, [2]int{start, i})
+		}
+		start = -1
+	}
+	return highlights, len(found)
+}
+
+// cutSnippet trims leading white space from the snippet and cuts a line longer than
+// maxSnippetLen around its first highlight.
+func cutSnippet(s Snippet) Snippet {
+	trimmed := strings.TrimLeftFunc(s.Text, unicode.IsSpace)
+	start := len(s.Text) - len(trimmed)
+	end := len(s.Text)
+	if end-start > maxSnippetLen {
+		if lead := s.Highlights[0][0] - snippetLead; lead > start {
+			start = runeStart(s.Text, lead)
+		}
+		if start+maxSnippetLen < end {
+			end = runeStart(s.Text, start+maxSnippetLen)
+		}
+	}
+
+	cut := Snippet{Line: s.Line, Text: strings.TrimRightFunc(s.Text[start:end], unicode.IsSpace)}
+	for _, h := range s.Highlights {
+		if h[0] < start || h[1] > start+len(cut.Text) {
+			continue
+		}
+		cut.Highlights = append(cut.Highlights, [2]int{h[0] - start, h[1] - start})
+	}
+	return cut
+}
+
+// runeStart moves i back to the start of the rune containing s[i].
+func runeStart(s string, i int) int {
+	for i > 0 && i < len(s) && !utf8.RuneStart(s[i]) {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_snippets_highlight_matched_words(t *testing.T) {
+	t.Parallel()
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "guide.md")
+	long := strings.Repeat("word ", 60) + "building" + strings.Repeat(" word", 60)
+	text := "# Guide\n\n    Building the routes.\nNothing here.\nCall BuildRoutes to build.\n" + long + "\n"
+	err = ioutil.WriteFile(filename, []byte(text), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	index := New(1)
+	index.SetAnalyzers(analyzers["english"])
+	cases := map[string]struct {
+		terms    []string
+		max      int
+		expected []Snippet
+	}{
+		"stems and identifier parts": {[]string{"build"}, 3, []Snippet{
+			{Line: 3, Text: "Building the routes.", Highlights: [][2]int{{0, 8}}},
+			{Line: 5, Text: "Call BuildRoutes to build.", Highlights: [][2]int{{5, 16}, {20, 25}}},
+			{Line: 6, Text: long[260:460], Highlights: [][2]int{{40, 48}}},
+		}},
+		"most distinct terms first": {[]string{"build", "rout"}, 2, []Snippet{
+			{Line: 3, Text: "Building the routes.", Highlights: [][2]int{{0, 8}, {13, 19}}},
+			{Line: 5, Text: "Call BuildRoutes to build.", Highlights: [][2]int{{5, 16}, {20, 25}}},
+		}},
+		"no terms": {nil, 3, nil},
+	}
+	for name, tc := range cases {
+		actual, err := index.Snippets(filename, tc.terms, tc.max)
+		if err != nil {
+			t.Errorf("%s: Snippets() error=%v, want nil", name, err)
+			continue
+		}
+		if !cmp.Equal(actual, tc.expected) {
+			t.Errorf("%s: Snippets() mismatch (-want +got)\n%s", name, cmp.Diff(tc.expected, actual))
+		}
+	}
+}
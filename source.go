@@ -0,0 +1,308 @@
+package main
+
+import (
+	"go/scanner"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// codeClass is the kind of a token in source code.
+type codeClass int
+
+const (
+	codeIdent codeClass = iota
+	codeKeyword
+	codeComment
+	codeString
+)
+
+// lexFunc classifies the tokens in source code, numbers and punctuation are skipped.
+type lexFunc func(src []byte, emit func(class codeClass, text string))
+
+// sourceTokenizer emits the identifiers lex finds followed by their parts, and the words
+// in comments and string literals as separate fields. Keywords are not indexed.
+func sourceTokenizer(lex lexFunc) Tokenizer {
+	return func(r io.Reader, emit emitFunc) error {
+		src, err := ioutil.ReadAll(r)
+		if err != nil {
+			return err
+		}
+		lex(src, func(class codeClass, text string) {
+			switch class {
+			case codeIdent:
+				emitIdent(text, emit)
+			case codeComment:
+				tokenizeProse(text, FieldComment, emit)
+			case codeString:
+				tokenizeProse(text, FieldString, emit)
+			}
+		})
+		return nil
+	}
+}
+
+// emitIdent emits an identifier followed by its parts, so "routes" finds BuildRoutes
+// while the whole identifier is weighted more highly.
+func emitIdent(ident string, emit emitFunc) {
+	emit(ident, FieldIdent)
+	parts := splitIdent(ident)
+	if len(parts) < 2 {
+		return
+	}
+	for _, part := range parts {
+		// single letters such as the X in parseX are too common to be useful
+		if utf8.RuneCountInString(part) > 1 {
+			emit(part, FieldSubword)
+		}
+	}
+}
+
+// lexGo classifies Go source with the standard library scanner.
+func lexGo(src []byte, emit func(class codeClass, text string)) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("", fset.Base(), len(src))
+	var s scanner.Scanner
+	// a nil error handler skips over malformed source
+	s.Init(file, src, nil, scanner.ScanComments)
+	for {
+		_, tok, lit := s.Scan()
+		switch {
+		case tok == token.EOF:
+			return
+		case tok == token.IDENT:
+			emit(codeIdent, lit)
+		case tok.IsKeyword():
+			emit(codeKeyword, lit)
+		case tok == token.COMMENT:
+			emit(codeComment, lit)
+		case tok == token.STRING:
+			text, err := strconv.Unquote(lit)
+			if err != nil {
+				text = strings.Trim(lit, "\"`")
+			}
+			emit(codeString, text)
+		}
+	}
+}
+
+// cLexer classifies the C like syntax of Java and JavaScript.
+type cLexer struct {
+	keywords StopWords
+	// js enables regular expression and template literals, otherwise Java text blocks
+	js bool
+}
+
+func lexJava(keywords []string) lexFunc {
+	return cLexer{keywords: NewStopWords(keywords)}.lex
+}
+
+func lexJS(keywords []string) lexFunc {
+	return cLexer{keywords: NewStopWords(keywords), js: true}.lex
+}
+
+func (l cLexer) lex(b []byte, emit func(class codeClass, text string)) {
+	src := string(b)
+	// regexOK is set where a / starts a regular expression rather than a division
+	regexOK := true
+	// templates holds the brace depth at each open ${ of a template literal
+	var templates []int
+	var depth int
+	for i := 0; i < len(src); {
+		ch, size := utf8.DecodeRuneInString(src[i:])
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			end := lineEnd(src, i)
+			emit(codeComment, src[i+2:end])
+			i = end
+
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				emit(codeComment, src[i+2:])
+				return
+			}
+			emit(codeComment, src[i+2:i+2+end])
+			i += end + 4
+
+		case !l.js && strings.HasPrefix(src[i:], `"""`):
+			end := strings.Index(src[i+3:], `"""`)
+			if end < 0 {
+				emit(codeString, unescape(src[i+3:]))
+				return
+			}
+			emit(codeString, unescape(src[i+3:i+3+end]))
+			i += end + 6
+			regexOK = false
+
+		case ch == '"' || ch == '\'':
+			end := quoteEnd(src, i, byte(ch))
+			emit(codeString, unescape(strings.TrimSuffix(src[i+1:end], string(ch))))
+			i = end
+			regexOK = false
+
+		case l.js && ch == '`':
+			var open bool
+			i, open = l.template(src, i+1, emit)
+			if open {
+				templates = append(templates, depth)
+			}
+			regexOK = !open
+
+		case l.js && ch == '}' && len(templates) > 0 && templates[len(templates)-1] == depth:
+			templates = templates[:len(templates)-1]
+			var open bool
+			i, open = l.template(src, i+1, emit)
+			if open {
+				templates = append(templates, depth)
+			}
+			regexOK = !open
+
+		case l.js && ch == '/' && regexOK:
+			// regular expressions are skipped, their escapes are not words
+			i = regexEnd(src, i)
+			for i < len(src) && isIdentPart(rune(src[i])) {
+				i++
+			}
+			regexOK = false
+
+		case isIdentStart(ch):
+			start := i
+			for i < len(src) {
+				r, n := utf8.DecodeRuneInString(src[i:])
+				if !isIdentPart(r) {
+					break
+				}
+				i += n
+			}
+			word := src[start:i]
+			if l.keywords[word] {
+				emit(codeKeyword, word)
+				// a keyword such as return may be followed by an expression
+				regexOK = true
+			} else {
+				emit(codeIdent, word)
+				regexOK = false
+			}
+
+		case unicode.IsDigit(ch):
+			for i < len(src) && (isIdentPart(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			regexOK = false
+
+		default:
+			switch ch {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+			if !unicode.IsSpace(ch) {
+				regexOK = ch != ')' && ch != ']' && ch != '}'
+			}
+			i += size
+		}
+	}
+}
+
+// template emits the text of a template literal from start up to its closing backtick or
+// the next ${ expression. It returns the offset to continue from and whether an expression
+// was opened.
+func (l cLexer) template(src string, start int, emit func(class codeClass, text string)) (int, bool) {
+	for i := start; i < len(src); i++ {
+		switch {
+		case src[i] == '\\':
+			i++
+		case src[i] == '`':
+			emit(codeString, unescape(src[start:i]))
+			return i + 1, false
+		case strings.HasPrefix(src[i:], "${"):
+			emit(codeString, unescape(src[start:i]))
+			return i + 2, true
+		}
+	}
+	emit(codeString, unescape(src[start:]))
+	return len(src), false
+}
+
+func isIdentStart(ch rune) bool {
+	return ch == '_' || ch == '$' || unicode.IsLetter(ch)
+}
+
+func isIdentPart(ch rune) bool {
+	return isIdentStart(ch) || unicode.IsDigit(ch)
+}
+
+// lineEnd returns the offset of the newline ending the line containing src[i].
+func lineEnd(src string, i int) int {
+	end := strings.IndexByte(src[i:], '\n')
+	if end < 0 {
+		return len(src)
+	}
+	return i + end
+}
+
+// quoteEnd returns the offset after the quote closing the literal opened at src[i]. An
+// unterminated literal ends at the end of the line.
+func quoteEnd(src string, i int, quote byte) int {
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// regexEnd returns the offset after the slash closing the regular expression at src[i].
+func regexEnd(src string, i int) int {
+	var class bool
+	for i++; i < len(src); i++ {
+		switch src[i] {
+		case '\\':
+			i++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if !class {
+				return i + 1
+			}
+		case '\n':
+			return i
+		}
+	}
+	return len(src)
+}
+
+// unescape replaces the escape sequences in a string literal, escaped letters such as
+// \n become spaces so they do not join the neighbouring words.
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		if strings.IndexByte("bfnrtv0", s[i]) >= 0 {
+			b.WriteByte(' ')
+		} else {
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
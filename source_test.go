@@ -0,0 +1,121 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type lexeme struct {
+	Class codeClass
+	Text  string
+}
+
+func lexAll(lex lexFunc, src string) []lexeme {
+	var tokens []lexeme
+	lex([]byte(src), func(class codeClass, text string) {
+		tokens = append(tokens, lexeme{class, text})
+	})
+	return tokens
+}
+
+func Test_lex_source(t *testing.T) {
+	t.Parallel()
+	cases := map[string]struct {
+		lex      lexFunc
+		src      string
+		expected []lexeme
+	}{
+		"go": {lexGo, "// Deprecated: use New\nfunc old() error { return errors.New(\"not\\tfound\") }", []lexeme{
+			{codeComment, "// Deprecated: use New"},
+			{codeKeyword, "func"},
+			{codeIdent, "old"},
+			{codeIdent, "error"},
+			{codeKeyword, "return"},
+			{codeIdent, "errors"},
+			{codeIdent, "New"},
+			{codeString, "not\tfound"},
+		}},
+		"java": {lexJava(javaStopWords), "/** Builds routes. */\nString s = \"not found\" + 'x' + \"\"\"\nblock\"\"\";", []lexeme{
+			{codeComment, "* Builds routes. "},
+			{codeIdent, "String"},
+			{codeIdent, "s"},
+			{codeString, "not found"},
+			{codeString, "x"},
+			{codeString, "\nblock"},
+		}},
+		"js regex": {lexJS(jsStopWords), "let re = /[/]\\w+/gi; x = a / b / c", []lexeme{
+			{codeKeyword, "let"},
+			{codeIdent, "re"},
+			{codeIdent, "x"},
+			{codeIdent, "a"},
+			{codeIdent, "b"},
+			{codeIdent, "c"},
+		}},
+		"js template": {lexJS(jsStopWords), "msg = `hello ${user.name({a: 1})} from ${host}`; // done", []lexeme{
+			{codeIdent, "msg"},
+			{codeString, "hello "},
+			{codeIdent, "user"},
+			{codeIdent, "name"},
+			{codeIdent, "a"},
+			{codeString, " from "},
+			{codeIdent, "host"},
+			{codeString, ""},
+			{codeComment, " done"},
+		}},
+		"js unterminated": {lexJS(jsStopWords), "s = 'oops\nnext", []lexeme{
+			{codeIdent, "s"},
+			{codeString, "oops"},
+			{codeIdent, "next"},
+		}},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			actual := lexAll(tc.lex, tc.src)
+			if !cmp.Equal(actual, tc.expected) {
+				t.Errorf("lex(%q) mismatch (-want +got)\n%s", tc.src, cmp.Diff(tc.expected, actual))
+			}
+		})
+	}
+}
+
+func Test_search_comment_and_string_fields(t *testing.T) {
+	index := New(3)
+	analyzer := analyzers["go"]
+	docs := map[string]string{
+		"old.go":    "// Deprecated: use New.\nfunc Old() error { return errors.New(\"file not found\") }",
+		"new.go":    "func New(deprecated bool) string { return \"found it, not sure\" }",
+		"notice.go": "// The file was not found.\nvar deprecated = 1",
+	}
+	for name, text := range docs {
+		doc, err := analyzer.Analyze(strings.NewReader(text))
+		if err != nil {
+			t.Fatal(err)
+		}
+		doc.Name = name
+		index.Update(doc)
+	}
+	index.SetAnalyzers(analyzer)
+
+	cases := map[string][]string{
+		`deprecated`:                     {"new.go", "notice.go", "old.go"},
+		`comment:deprecated`:             {"old.go"},
+		`string:"not found"`:             {"old.go"},
+		`string:found`:                   {"new.go", "old.go"},
+		`comment:found -string:file`:     {"notice.go"},
+		`deprecated -comment:deprecated`: {"new.go", "notice.go"},
+	}
+	for query, expected := range cases {
+		result, err := SearchWith(query, index, SearchOptions{Exact: true})
+		if err != nil {
+			t.Errorf("SearchWith(%q) error=%v, want nil", query, err)
+			continue
+		}
+		actual := documents(result.Docs)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("SearchWith(%q) mismatch (-want +got)\n%s", query, cmp.Diff(expected, actual))
+		}
+	}
+}
@@ -0,0 +1,239 @@
+// Package stem reduces inflected English words to a common stem.
+package stem
+
+// Porter returns the stem of word using the Porter stemming algorithm so "build",
+// "builds" and "building" all become "build". Stems are not necessarily words, "happy"
+// becomes "happi". The word must be lower case, words of two letters or fewer and words
+// containing anything other than a-z are returned unchanged.
+func Porter(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	w := []byte(word)
+	w = step1a(w)
+	w = step1b(w)
+	w = step1c(w)
+	w = replaceSuffix(w, step2, 0)
+	w = replaceSuffix(w, step3, 0)
+	w = step4(w)
+	w = step5(w)
+	return string(w)
+}
+
+func isConsonant(w []byte, i int) bool {
+	switch w[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		return i == 0 || !isConsonant(w, i-1)
+	}
+	return true
+}
+
+// measure returns m where w is of the form [C](VC){m}[V], C and V being runs of
+// consonants and vowels.
+func measure(w []byte) int {
+	var m int
+	i := 0
+	for i < len(w) && isConsonant(w, i) {
+		i++
+	}
+	for i < len(w) {
+		for i < len(w) && !isConsonant(w, i) {
+			i++
+		}
+		if i >= len(w) {
+			break
+		}
+		for i < len(w) && isConsonant(w, i) {
+			i++
+		}
+		m++
+	}
+	return m
+}
+
+func hasVowel(w []byte) bool {
+	for i := range w {
+		if !isConsonant(w, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// endsDouble returns true if w ends with a double consonant.
+func endsDouble(w []byte) bool {
+	l := len(w)
+	return l >= 2 && w[l-1] == w[l-2] && isConsonant(w, l-1)
+}
+
+// endsCVC returns true if w ends consonant, vowel, consonant and the final consonant is
+// not w, x or y, as in "hop" but not "snow".
+func endsCVC(w []byte) bool {
+	l := len(w)
+	if l < 3 || !isConsonant(w, l-3) || isConsonant(w, l-2) || !isConsonant(w, l-1) {
+		return false
+	}
+	ch := w[l-1]
+	return ch != 'w' && ch != 'x' && ch != 'y'
+}
+
+func hasSuffix(w []byte, suffix string) bool {
+	return len(w) >= len(suffix) && string(w[len(w)-len(suffix):]) == suffix
+}
+
+func step1a(w []byte) []byte {
+	switch {
+	case hasSuffix(w, "sses"), hasSuffix(w, "ies"):
+		return w[:len(w)-2]
+	case hasSuffix(w, "ss"):
+		return w
+	case hasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+func step1b(w []byte) []byte {
+	if hasSuffix(w, "eed") {
+		if measure(w[:len(w)-3]) > 0 {
+			return w[:len(w)-1]
+		}
+		return w
+	}
+
+	var stem []byte
+	switch {
+	case hasSuffix(w, "ed"):
+		stem = w[:len(w)-2]
+	case hasSuffix(w, "ing"):
+		stem = w[:len(w)-3]
+	default:
+		return w
+	}
+	if !hasVowel(stem) {
+		return w
+	}
+
+	// tidy the stem so "hopping" becomes "hop" and "filing" becomes "file"
+	switch {
+	case hasSuffix(stem, "at"), hasSuffix(stem, "bl"), hasSuffix(stem, "iz"):
+		return append(stem, 'e')
+	case endsDouble(stem):
+		switch stem[len(stem)-1] {
+		case 'l', 's', 'z':
+			return stem
+		}
+		return stem[:len(stem)-1]
+	case measure(stem) == 1 && endsCVC(stem):
+		return append(stem, 'e')
+	}
+	return stem
+}
+
+func step1c(w []byte) []byte {
+	if hasSuffix(w, "y") && hasVowel(w[:len(w)-1]) {
+		w[len(w)-1] = 'i'
+	}
+	return w
+}
+
+type suffix struct {
+	from string
+	to   string
+}
+
+// step2 and step3 map derivational suffixes to simpler ones. A suffix which is the end of
+// another must follow it.
+var step2 = []suffix{
+	{"ational", "ate"},
+	{"tional", "tion"},
+	{"enci", "ence"},
+	{"anci", "ance"},
+	{"izer", "ize"},
+	{"bli", "ble"},
+	{"alli", "al"},
+	{"entli", "ent"},
+	{"eli", "e"},
+	{"ousli", "ous"},
+	{"ization", "ize"},
+	{"ation", "ate"},
+	{"ator", "ate"},
+	{"alism", "al"},
+	{"iveness", "ive"},
+	{"fulness", "ful"},
+	{"ousness", "ous"},
+	{"aliti", "al"},
+	{"iviti", "ive"},
+	{"biliti", "ble"},
+	{"logi", "log"},
+}
+
+var step3 = []suffix{
+	{"icate", "ic"},
+	{"ative", ""},
+	{"alize", "al"},
+	{"iciti", "ic"},
+	{"ical", "ic"},
+	{"ful", ""},
+	{"ness", ""},
+}
+
+// replaceSuffix replaces the first suffix w ends with when the remaining stem has a
+// measure greater than min.
+func replaceSuffix(w []byte, suffixes []suffix, min int) []byte {
+	for _, s := range suffixes {
+		if !hasSuffix(w, s.from) {
+			continue
+		}
+		stem := w[:len(w)-len(s.from)]
+		if measure(stem) > min {
+			return append(stem, s.to...)
+		}
+		return w
+	}
+	return w
+}
+
+var step4Suffixes = []string{
+	"al", "ance", "ence", "er", "ic", "able", "ible", "ant", "ement", "ment", "ent",
+	"ion", "ou", "ism", "ate", "iti", "ous", "ive", "ize",
+}
+
+func step4(w []byte) []byte {
+	for _, s := range step4Suffixes {
+		if !hasSuffix(w, s) {
+			continue
+		}
+		stem := w[:len(w)-len(s)]
+		if s == "ion" && !hasSuffix(stem, "s") && !hasSuffix(stem, "t") {
+			return w
+		}
+		if measure(stem) > 1 {
+			return stem
+		}
+		return w
+	}
+	return w
+}
+
+func step5(w []byte) []byte {
+	if hasSuffix(w, "e") {
+		stem := w[:len(w)-1]
+		m := measure(stem)
+		if m > 1 || (m == 1 && !endsCVC(stem)) {
+			w = stem
+		}
+	}
+	if measure(w) > 1 && endsDouble(w) && hasSuffix(w, "l") {
+		w = w[:len(w)-1]
+	}
+	return w
+}
@@ -0,0 +1,66 @@
+package stem
+
+import "testing"
+
+func Test_porter(t *testing.T) {
+	cases := map[string]string{
+		"caresses":        "caress",
+		"ponies":          "poni",
+		"ties":            "ti",
+		"caress":          "caress",
+		"cats":            "cat",
+		"feed":            "feed",
+		"agreed":          "agre",
+		"plastered":       "plaster",
+		"bled":            "bled",
+		"motoring":        "motor",
+		"sing":            "sing",
+		"conflated":       "conflat",
+		"troubled":        "troubl",
+		"sized":           "size",
+		"hopping":         "hop",
+		"tanned":          "tan",
+		"falling":         "fall",
+		"hissing":         "hiss",
+		"fizzed":          "fizz",
+		"failing":         "fail",
+		"filing":          "file",
+		"happy":           "happi",
+		"sky":             "sky",
+		"relational":      "relat",
+		"conditional":     "condit",
+		"generalizations": "gener",
+		"hopefulness":     "hope",
+		"goodness":        "good",
+		"adjustment":      "adjust",
+		"effective":       "effect",
+		"probate":         "probat",
+		"rate":            "rate",
+		"cease":           "ceas",
+		"controlling":     "control",
+		"rolling":         "roll",
+		"connections":     "connect",
+		"connected":       "connect",
+		"build":           "build",
+		"builds":          "build",
+		"building":        "build",
+		"go":              "go",
+		"don't":           "don't",
+		"über":            "über",
+		"double-edged":    "double-edged",
+	}
+	for word, expected := range cases {
+		actual := Porter(word)
+		if actual != expected {
+			t.Errorf("Porter(%s)=%s, want %s", word, actual, expected)
+		}
+	}
+}
+
+var Stem string
+
+func BenchmarkPorter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Stem = Porter("generalizations")
+	}
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Definition locates the declaration of a symbol.
+type Definition struct {
+	Document string
+	Symbol
+}
+
+// symbolTable holds the declarations of every document by lower case name. It is rebuilt
+// on first use after documents are added or removed.
+type symbolTable struct {
+	sync.Mutex
+	built  bool
+	byName map[string][]Definition
+}
+
+// invalidate discards the table, the caller must hold the index write lock.
+func (t *symbolTable) invalidate() {
+	t.Lock()
+	t.built = false
+	t.byName = nil
+	t.Unlock()
+}
+
+// symbolTable returns the declarations by name, the caller must hold at least the index
+// read lock.
+func (z *Index) symbolTable() map[string][]Definition {
+	t := &z.definitions
+	t.Lock()
+	defer t.Unlock()
+	if !t.built {
+		t.byName = make(map[string][]Definition)
+		for pos, symbols := range z.Symbols {
+			if pos >= len(z.Names) || z.Names[pos] == "" {
+				continue
+			}
+			for _, sym := range symbols {
+				name := strings.ToLower(sym.Name)
+				t.byName[name] = append(t.byName[name], Definition{Document: z.Names[pos], Symbol: sym})
+			}
+		}
+		t.built = true
+	}
+	return t.byName
+}
+
+// Definitions returns where the symbol name is declared ignoring case, ordered by document
+// and line. A method may be qualified by its receiver as in Index.Search.
+func (z *Index) Definitions(name string) []Definition {
+	z.RLock()
+	defer z.RUnlock()
+	name = strings.ToLower(name)
+	var receiver string
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		receiver, name = name[:i], name[i+1:]
+	}
+
+	var defs []Definition
+	for _, def := range z.symbolTable()[name] {
+		if receiver == "" || strings.ToLower(def.Receiver) == receiver {
+			defs = append(defs, def)
+		}
+	}
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Document != defs[j].Document {
+			return defs[i].Document < defs[j].Document
+		}
+		return defs[i].Line < defs[j].Line
+	})
+	return defs
+}
+
+// goSymbols returns the top level declarations in Go source. Files which do not parse
+// yield the declarations the parser recovered.
+func goSymbols(src []byte) []Symbol {
+	fset := token.NewFileSet()
+	file, _ := parser.ParseFile(fset, "", src, 0)
+	if file == nil {
+		return nil
+	}
+
+	var symbols []Symbol
+	add := func(name *ast.Ident, kind string, receiver string) {
+		if name == nil || name.Name == "_" {
+			return
+		}
+		symbols = append(symbols, Symbol{
+			Name:     name.Name,
+			Kind:     kind,
+			Receiver: receiver,
+			Line:     fset.Position(name.Pos()).Line,
+		})
+	}
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil || len(d.Recv.List) == 0 {
+				add(d.Name, "func", "")
+				continue
+			}
+			add(d.Name, "method", receiverName(d.Recv.List[0].Type))
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					add(s.Name, "type", "")
+				case *ast.ValueSpec:
+					for _, name := range s.Names {
+						add(name, d.Tok.String(), "")
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// receiverName returns the type name of a method receiver such as *Index, List[T] or
+// *Map[K, V].
+func receiverName(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.StarExpr:
+			expr = e.X
+		case *ast.ParenExpr:
+			expr = e.X
+		case *ast.IndexExpr:
+			expr = e.X
+		case *ast.IndexListExpr:
+			expr = e.X
+		case *ast.Ident:
+			return e.Name
+		default:
+			return ""
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+const symbolSource = `package main
+
+import "fmt"
+
+const Version, _ = "1", 2
+
+var (
+	debug bool
+)
+
+type Index struct{}
+
+type List[T any] []T
+
+func New() *Index { return nil }
+
+func (z *Index) Search(q string) {}
+
+func (l List[T]) Len() int { return len(l) }
+
+type Map[K comparable, V any] map[K]V
+
+func (m *Map[K, V]) Get(k K) V { return (*m)[k] }
+
+func broken( {
+`
+
+func Test_go_symbols(t *testing.T) {
+	t.Parallel()
+	expected := []Symbol{
+		{Name: "Version", Kind: "const", Line: 5},
+		{Name: "debug", Kind: "var", Line: 8},
+		{Name: "Index", Kind: "type", Line: 11},
+		{Name: "List", Kind: "type", Line: 13},
+		{Name: "New", Kind: "func", Line: 15},
+		{Name: "Search", Kind: "method", Receiver: "Index", Line: 17},
+		{Name: "Len", Kind: "method", Receiver: "List", Line: 19},
+		{Name: "Map", Kind: "type", Line: 21},
+		{Name: "Get", Kind: "method", Receiver: "Map", Line: 23},
+		{Name: "broken", Kind: "func", Line: 25},
+	}
+	actual := goSymbols([]byte(symbolSource))
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("goSymbols() mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+}
+
+func symbolIndex() *Index {
+	index := New(3)
+	analyzer := analyzers["go"]
+	docs := map[string]string{
+		"index.go":  symbolSource,
+		"search.go": "package main\n\nfunc Search(index *Index) {\n\tindex.Search(\"q\")\n}\n",
+		"main.go":   "package main\n\nfunc main() {\n\tNew().Search(\"index\")\n}\n",
+	}
+	for name, text := range docs {
+		doc, _ := analyzer.Analyze(strings.NewReader(text))
+		doc.Name = name
+		index.Update(doc)
+	}
+	index.SetAnalyzers(analyzer)
+	return index
+}
+
+func Test_definitions(t *testing.T) {
+	index := symbolIndex()
+	cases := map[string][]Definition{
+		"search": {
+			{Document: "index.go", Symbol: Symbol{Name: "Search", Kind: "method", Receiver: "Index", Line: 17}},
+			{Document: "search.go", Symbol: Symbol{Name: "Search", Kind: "func", Line: 3}},
+		},
+		"Index.Search": {
+			{Document: "index.go", Symbol: Symbol{Name: "Search", Kind: "method", Receiver: "Index", Line: 17}},
+		},
+		"main":    {{Document: "main.go", Symbol: Symbol{Name: "main", Kind: "func", Line: 3}}},
+		"missing": nil,
+	}
+	for name, expected := range cases {
+		actual := index.Definitions(name)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("Definitions(%q) mismatch (-want +got)\n%s", name, cmp.Diff(expected, actual))
+		}
+	}
+
+	index.Remove("search.go")
+	if defs := index.Definitions("search"); len(defs) != 1 {
+		t.Errorf("Definitions(search)=%v after Remove, want only index.go", defs)
+	}
+
+	index.Compact()
+	var buf bytes.Buffer
+	err := index.Save(&buf)
+	if err != nil {
+		t.Fatalf("Save() error=%v, want nil", err)
+	}
+	loaded, err := Load(&buf)
+	if err != nil {
+		t.Fatalf("Load() error=%v, want nil", err)
+	}
+	if defs := loaded.Definitions("main"); len(defs) != 1 || defs[0].Document != "main.go" {
+		t.Errorf("Definitions(main)=%v after Load, want main.go", defs)
+	}
+}
+
+func Test_search_symbol_filter(t *testing.T) {
+	index := symbolIndex()
+	cases := map[string]struct {
+		docs []string
+		defs int
+	}{
+		"search":            {[]string{"index.go", "main.go", "search.go"}, 0},
+		"sym:search":        {[]string{"index.go", "search.go"}, 2},
+		"sym:Index.Search":  {[]string{"index.go"}, 1},
+		"sym:Map.Get":       {[]string{"index.go"}, 1},
+		"sym:search -debug": {[]string{"search.go"}, 1},
+	}
+	for query, tc := range cases {
+		result, err := SearchWith(query, index, SearchOptions{Exact: true})
+		if err != nil {
+			t.Errorf("SearchWith(%q) error=%v, want nil", query, err)
+			continue
+		}
+		actual := documents(result.Docs)
+		if !cmp.Equal(actual, tc.docs) {
+			t.Errorf("SearchWith(%q) mismatch (-want +got)\n%s", query, cmp.Diff(tc.docs, actual))
+		}
+		if len(result.Definitions) != tc.defs {
+			t.Errorf("SearchWith(%q) Definitions=%v, want %d", query, result.Definitions, tc.defs)
+		}
+	}
+}
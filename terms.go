@@ -0,0 +1,180 @@
+package main
+
+import (
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// maxExpansions bounds the number of words a wildcard is expanded to, the words found
+// in the most documents are kept.
+const maxExpansions = 128
+
+// termDictionary holds the indexed words in sorted order, and ordered by their reversal,
+// so prefix and suffix patterns are expanded without scanning every word. It is rebuilt
+// on first use after words are added or removed.
+type termDictionary struct {
+	sync.Mutex
+	built    bool
+	sorted   []string
+	reversed []string
+}
+
+// invalidate discards the dictionary, the caller must hold the index write lock.
+func (d *termDictionary) invalidate() {
+	d.Lock()
+	d.built = false
+	d.sorted = nil
+	d.reversed = nil
+	d.Unlock()
+}
+
+// dictionary returns the sorted and reversed word lists, the caller must hold at least
+// the index read lock.
+func (z *Index) dictionary() (sorted []string, reversed []string) {
+	d := &z.terms
+	d.Lock()
+	defer d.Unlock()
+	if !d.built {
+		d.sorted = make([]string, 0, len(z.Words))
+		d.reversed = make([]string, 0, len(z.Words))
+		for word := range z.Words {
+			d.sorted = append(d.sorted, word)
+			d.reversed = append(d.reversed, reverse(word))
+		}
+		sort.Strings(d.sorted)
+		sort.Strings(d.reversed)
+		d.built = true
+	}
+	return d.sorted, d.reversed
+}
+
+func reverse(s string) string {
+	runes := []rune(s)
+	for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+		runes[i], runes[j] = runes[j], runes[i]
+	}
+	return string(runes)
+}
+
+// prefixRange returns the contiguous run of sorted words starting with prefix.
+func prefixRange(words []string, prefix string) []string {
+	lo := sort.SearchStrings(words, prefix)
+	hi := lo + sort.Search(len(words)-lo, func(i int) bool {
+		return !strings.HasPrefix(words[lo+i], prefix)
+	})
+	return words[lo:hi]
+}
+
+// isWildcard returns true if word is a glob pattern.
+func isWildcard(word string) bool {
+	return strings.ContainsAny(word, "*?[")
+}
+
+// Complete returns up to limit words starting with prefix, the words found in the most
+// documents first.
+func (z *Index) Complete(prefix string, limit int) []string {
+	z.RLock()
+	defer z.RUnlock()
+	sorted, _ := z.dictionary()
+	words := append([]string(nil), prefixRange(sorted, prefix)...)
+	z.byFrequency(words)
+	if len(words) > limit {
+		words = words[:limit]
+	}
+	return words
+}
+
+// byFrequency orders words by the number of documents they occur in, most first.
+func (z *Index) byFrequency(words []string) {
+	sort.SliceStable(words, func(i, j int) bool {
+		return len(z.Words[words[i]].Docs) > len(z.Words[words[j]].Docs)
+	})
+}
+
+// Expand returns the indexed words matching the glob pattern in sorted order.
+func (z *Index) Expand(pattern string) []string {
+	z.RLock()
+	defer z.RUnlock()
+	return z.expand(pattern)
+}
+
+func (z *Index) expand(pattern string) []string {
+	sorted, reversed := z.dictionary()
+	prefix := pattern[:strings.IndexAny(pattern+"*", "*?[")]
+	suffix := pattern[strings.LastIndexAny(pattern, "*?]")+1:]
+
+	// narrow the candidates with the longer of the literal prefix and suffix
+	var candidates []string
+	switch {
+	case len(suffix) > len(prefix):
+		for _, word := range prefixRange(reversed, reverse(suffix)) {
+			candidates = append(candidates, reverse(word))
+		}
+		sort.Strings(candidates)
+	case prefix != "":
+		candidates = prefixRange(sorted, prefix)
+	default:
+		candidates = sorted
+	}
+
+	var words []string
+	for _, word := range candidates {
+		if ok, _ := path.Match(pattern, word); ok {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// Wildcard returns the documents containing a word matching the glob pattern and the
+// words matched. Each document is scored by its most relevant matching word.
+func (z *Index) Wildcard(pattern string) (DocList, Words, error) {
+	z.RLock()
+	defer z.RUnlock()
+	if 1 > len(z.Words) {
+		return nil, nil, ErrWordNotIndexed
+	}
+
+	words := z.expand(pattern)
+	if len(words) > maxExpansions {
+		z.byFrequency(words)
+		words = words[:maxExpansions]
+		sort.Strings(words)
+	}
+
+	pos := make(map[string]int)
+	var docs = make(DocList, 0, len(words))
+	for _, word := range words {
+		col := z.Words[word]
+		col.Apply(func(p Posting) {
+			doc := z.byId(p.Doc)
+			relevance := DocRelevance{
+				Document: doc,
+				Count:    p.Count,
+				Rank:     p.Fields.Weight(),
+				Score:    z.bm25(p, len(col.Docs)),
+			}
+			i, ok := pos[doc]
+			if !ok {
+				pos[doc] = len(docs)
+				docs = append(docs, relevance)
+				return
+			}
+			if docs[i].Score < relevance.Score {
+				docs[i] = relevance
+			}
+		})
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score > docs[j].Score
+	})
+
+	matched := make(Words, 0, len(words))
+	for _, word := range words {
+		matched = append(matched, WordDist{Word: word})
+	}
+	return docs, matched, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func termsIndex() *Index {
+	index := New(3)
+	index.Update(&Document{Name: "a.md", WordCount: map[string]int{"docker": 1, "dockerfile": 1, "handler": 1}})
+	index.Update(&Document{Name: "b.md", WordCount: map[string]int{"docker": 2, "dock": 1, "errorhandler": 1}})
+	index.Update(&Document{Name: "c.md", WordCount: map[string]int{"docker": 1, "handle": 1}})
+	return index
+}
+
+func Test_expand_wildcards(t *testing.T) {
+	cases := map[string][]string{
+		"dock*":      {"dock", "docker", "dockerfile"},
+		"docker*":    {"docker", "dockerfile"},
+		"*handler":   {"errorhandler", "handler"},
+		"*handle*":   {"errorhandler", "handle", "handler"},
+		"d?ck":       {"dock"},
+		"do*er":      {"docker"},
+		"hand[lx]er": {"handler"},
+		"docker":     {"docker"},
+		"kube*":      nil,
+	}
+	index := termsIndex()
+	for pattern, expected := range cases {
+		actual := index.Expand(pattern)
+		if !cmp.Equal(actual, expected) {
+			t.Errorf("Expand(%q) mismatch (-want +got)\n%s", pattern, cmp.Diff(expected, actual))
+		}
+	}
+}
+
+func Test_expansions_follow_updates(t *testing.T) {
+	index := termsIndex()
+	_ = index.Expand("dock*")
+	index.Update(&Document{Name: "d.md", WordCount: map[string]int{"dockyard": 1}})
+	index.Remove("b.md")
+
+	actual := index.Expand("dock*")
+	expected := []string{"docker", "dockerfile", "dockyard"}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("Expand(`dock*`) mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+}
+
+func Test_complete_orders_by_document_frequency(t *testing.T) {
+	index := termsIndex()
+	actual := index.Complete("do", 2)
+	expected := []string{"docker", "dock"}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("Complete(`do`, 2) mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+}
+
+func Test_wildcard_search(t *testing.T) {
+	index := termsIndex()
+	scores, err := Search("*handler", index)
+	if err != nil {
+		t.Fatalf("Search(`*handler`) error=%v, want nil", err)
+	}
+	actual := documents(scores)
+	expected := []string{"a.md", "b.md"}
+	if !cmp.Equal(actual, expected) {
+		t.Errorf("Search(`*handler`) mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+}
@@ -0,0 +1,288 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// watchQuiet is how long the file system must be idle before pending changes are indexed.
+	watchQuiet = 250 * time.Millisecond
+	// watchMaxDelay bounds how long a continuous stream of changes can defer indexing.
+	watchMaxDelay = 2 * time.Second
+	// pollInterval is how often the polling fallback walks the file system.
+	pollInterval = 2 * time.Second
+)
+
+// resync is sent by a notifier in place of a path when changes may have been missed and
+// every corpus needs to be compared with the index again.
+const resync = ""
+
+// notifier reports the paths of files and directories which may have changed.
+type notifier interface {
+	Events() <-chan string
+	Close() error
+}
+
+// Watcher keeps an index current with the files below a set of paths.
+type Watcher struct {
+	index     *Index
+	corpora   Corpora
+	ix        *Indexer
+	notifier  notifier
+	quiet     time.Duration
+	maxDelay  time.Duration
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewWatcher starts watching the corpora of ix for files to index. It uses the platforms native
+// notifications where available and falls back to polling otherwise.
+func NewWatcher(index *Index, ix *Indexer) (*Watcher, error) {
+	n, err := newNotifier(ix.Corpora)
+	if err != nil {
+		log.Printf("watch=fallback interval=%v error='%v'\n", pollInterval, err)
+		n = newPoller(ix.Corpora, pollInterval)
+	}
+	return newWatcher(index, n, ix, watchQuiet), nil
+}
+
+func newWatcher(index *Index, n notifier, ix *Indexer, quiet time.Duration) *Watcher {
+	w := &Watcher{
+		index:    index,
+		corpora:  ix.Corpora,
+		ix:       ix,
+		notifier: n,
+		quiet:    quiet,
+		maxDelay: watchMaxDelay,
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Close stops watching and waits for pending changes to be indexed.
+func (w *Watcher) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		err = w.notifier.Close()
+	})
+	<-w.done
+	return err
+}
+
+func (w *Watcher) run() {
+	defer close(w.done)
+	pending := make(map[string]bool)
+	var first time.Time
+	var quiet *time.Timer
+	var fire <-chan time.Time
+
+	for {
+		select {
+		case name, ok := <-w.notifier.Events():
+			if !ok {
+				w.flush(pending)
+				return
+			}
+			if len(pending) == 0 {
+				first = time.Now()
+			}
+			pending[name] = true
+
+			delay := w.quiet
+			if remaining := w.maxDelay - time.Since(first); remaining < delay {
+				delay = remaining
+			}
+			if quiet == nil {
+				quiet = time.NewTimer(delay)
+			} else {
+				if !quiet.Stop() {
+					select {
+					case <-quiet.C:
+					default:
+					}
+				}
+				quiet.Reset(delay)
+			}
+			fire = quiet.C
+
+		case <-fire:
+			w.flush(pending)
+			pending = make(map[string]bool)
+			fire = nil
+		}
+	}
+}
+
+func (w *Watcher) flush(pending map[string]bool) {
+	if len(pending) == 0 {
+		return
+	}
+	if pending[resync] {
+		w.ix.Refresh(w.index)
+		return
+	}
+	ts := time.Now()
+	var updated, removed int
+	for name := range pending {
+		u, r := w.apply(name)
+		updated += u
+		removed += r
+	}
+	if updated > 0 || removed > 0 {
+		log.Printf("watch=success updated=%d removed=%d documents=%d words=%d latency=%v\n",
+			updated, removed, w.index.Len(), w.index.WordCount(), time.Since(ts))
+	}
+}
+
+// apply brings the index in line with the current state of name returning the number of
+// documents updated and removed.
+func (w *Watcher) apply(name string) (updated int, removed int) {
+	if w.corpora.IsIgnoreFile(name) {
+		return 0, w.reloadIgnores(name)
+	}
+	info, err := os.Stat(name)
+	if os.IsNotExist(err) {
+		// a removed directory only reports itself so drop everything below it too
+		prefix := name + string(filepath.Separator)
+		for _, doc := range w.index.Documents() {
+			if doc == name || strings.HasPrefix(doc, prefix) {
+				w.index.Remove(doc)
+				removed++
+			}
+		}
+		return 0, removed
+	}
+	if err != nil {
+		log.Printf("watch=failed filename=%s error='%v'\n", name, err)
+		return 0, 0
+	}
+	if info.IsDir() {
+		return 0, 0
+	}
+	if !w.corpora.MatchFile(name, info) {
+		// a document which grew too large is dropped
+		if _, ok := w.index.Fingerprint(name); ok {
+			w.index.Remove(name)
+			return 0, 1
+		}
+		return 0, 0
+	}
+
+	fp, ok := w.index.Fingerprint(name)
+	if ok && fp.Matches(info.ModTime().UnixNano(), info.Size()) {
+		return 0, 0
+	}
+	doc, err := w.ix.registry().readFile(name, w.ix.Languages)
+	if err != nil {
+		log.Printf("readFile=failed filename=%s error='%v'\n", name, err)
+		return 0, 0
+	}
+	w.index.Update(doc)
+	return 1, 0
+}
+
+// reloadIgnores reads the changed ignore file name again and drops the documents below its
+// directory which it now excludes. Files it no longer excludes are added by the next refresh.
+func (w *Watcher) reloadIgnores(name string) (removed int) {
+	dir := filepath.Dir(name)
+	w.corpora.forgetIgnores(dir)
+	prefix := dir + string(filepath.Separator)
+	for _, doc := range w.index.Documents() {
+		if strings.HasPrefix(doc, prefix) && !w.corpora.Match(doc) {
+			w.index.Remove(doc)
+			removed++
+		}
+	}
+	return removed
+}
+
+// poller is a notifier which periodically walks the file system comparing the size and
+// modification time of each matching file.
+type poller struct {
+	corpora  Corpora
+	interval time.Duration
+	events   chan string
+	stop     chan struct{}
+	files    map[string]Fingerprint
+}
+
+func newPoller(corpora Corpora, interval time.Duration) *poller {
+	p := &poller{
+		corpora:  corpora,
+		interval: interval,
+		events:   make(chan string, 64),
+		stop:     make(chan struct{}),
+	}
+	p.files = p.scan()
+	go p.run()
+	return p
+}
+
+func (p *poller) Events() <-chan string {
+	return p.events
+}
+
+func (p *poller) Close() error {
+	close(p.stop)
+	return nil
+}
+
+func (p *poller) run() {
+	defer close(p.events)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+		}
+
+		cur := p.scan()
+		for name, fp := range cur {
+			if prev, ok := p.files[name]; ok && prev == fp {
+				continue
+			}
+			if !p.send(name) {
+				return
+			}
+		}
+		for name := range p.files {
+			if _, ok := cur[name]; ok {
+				continue
+			}
+			if !p.send(name) {
+				return
+			}
+		}
+		p.files = cur
+	}
+}
+
+func (p *poller) send(name string) bool {
+	select {
+	case p.events <- name:
+		return true
+	case <-p.stop:
+		return false
+	}
+}
+
+func (p *poller) scan() map[string]Fingerprint {
+	files := make(map[string]Fingerprint)
+	for _, c := range p.corpora {
+		c := c
+		c.walk(func(name string, info os.FileInfo) {
+			if c.MatchFile(name, info) || c.isIgnoreFile(name) {
+				files[name] = Fingerprint{ModTime: info.ModTime().UnixNano(), Size: info.Size()}
+			}
+		})
+	}
+	return files
+}
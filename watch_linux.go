@@ -0,0 +1,151 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+const inotifyMask = syscall.IN_CREATE | syscall.IN_MODIFY | syscall.IN_CLOSE_WRITE |
+	syscall.IN_DELETE | syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF
+
+// inotify is a notifier backed by the Linux inotify API. Every directory below the
+// watched paths has its own watch which is added as directories are created.
+type inotify struct {
+	fd      int
+	f       *os.File
+	corpora Corpora
+	events  chan string
+	mu      sync.Mutex
+	watches map[int]string
+}
+
+func newNotifier(corpora Corpora) (notifier, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	n := &inotify{
+		fd: fd,
+		// a non-blocking descriptor lets Close interrupt a pending Read
+		f:       os.NewFile(uintptr(fd), "inotify"),
+		corpora: corpora,
+		events:  make(chan string, 64),
+		watches: make(map[int]string),
+	}
+	for _, c := range corpora {
+		err = n.addTree(c, c.Path, false)
+		if err != nil {
+			n.f.Close()
+			return nil, err
+		}
+	}
+	go n.run()
+	return n, nil
+}
+
+func (n *inotify) Events() <-chan string {
+	return n.events
+}
+
+func (n *inotify) Close() error {
+	return n.f.Close()
+}
+
+// addTree watches dir, a directory in corpus c, and every directory below it the corpus
+// walks into. When announce is true the files found are reported as they may have been
+// created before the watch was in place. The first directory which could not be watched
+// is returned as the error.
+func (n *inotify) addTree(c *Corpus, dir string, announce bool) error {
+	var err error
+	skipped := c.walkDir(dir, func(path string) bool {
+		wd, werr := syscall.InotifyAddWatch(n.fd, path, inotifyMask)
+		if werr != nil {
+			if err == nil {
+				err = fmt.Errorf("%s: %v", path, werr)
+			}
+			return false
+		}
+		n.mu.Lock()
+		n.watches[wd] = path
+		n.mu.Unlock()
+		return true
+	}, func(name string, info os.FileInfo) {
+		if announce {
+			n.events <- name
+		}
+	})
+	if err == nil && len(skipped) > 0 && skipped[0].Path == dir {
+		err = fmt.Errorf("%s: %s", dir, skipped[0].Reason)
+	}
+	return err
+}
+
+func (n *inotify) run() {
+	defer close(n.events)
+	var buf [(syscall.SizeofInotifyEvent + syscall.NAME_MAX + 1) * 64]byte
+	for {
+		count, err := n.f.Read(buf[:])
+		if err != nil {
+			return
+		}
+
+		var offset int
+		for offset+syscall.SizeofInotifyEvent <= count {
+			ev := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			nameEnd := nameStart + int(ev.Len)
+			name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+			offset = nameEnd
+			n.handle(ev, name)
+		}
+	}
+}
+
+func (n *inotify) handle(ev *syscall.InotifyEvent, name string) {
+	if ev.Mask&syscall.IN_Q_OVERFLOW != 0 {
+		// events were dropped so watch any directories missed and compare everything again
+		log.Println("watch=overflow")
+		for _, c := range n.corpora {
+			_ = n.addTree(c, c.Path, false)
+		}
+		n.events <- resync
+		return
+	}
+
+	n.mu.Lock()
+	dir, ok := n.watches[int(ev.Wd)]
+	if ev.Mask&syscall.IN_IGNORED != 0 {
+		delete(n.watches, int(ev.Wd))
+	}
+	n.mu.Unlock()
+	if !ok || name == "" {
+		return
+	}
+
+	path := filepath.Join(dir, name)
+	isDir := ev.Mask&syscall.IN_ISDIR != 0
+	if isDir && ev.Mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		for _, c := range n.corpora {
+			if _, ok := c.rel(path); !ok || c.SkipDir(path) {
+				continue
+			}
+			err := n.addTree(c, path, true)
+			if err != nil {
+				log.Printf("watch=failed path=%s error='%v'\n", path, err)
+			}
+			return
+		}
+		return
+	}
+	n.events <- path
+}
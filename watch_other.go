@@ -0,0 +1,12 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import (
+	"fmt"
+)
+
+func newNotifier(corpora Corpora) (notifier, error) {
+	return nil, fmt.Errorf("file system notifications are not supported on this platform")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func Test_watcher_keeps_index_current(t *testing.T) {
+	cases := map[string]func(corpora Corpora) (notifier, error){
+		"native": func(corpora Corpora) (notifier, error) {
+			return newNotifier(corpora)
+		},
+		"poller": func(corpora Corpora) (notifier, error) {
+			return newPoller(corpora, 10*time.Millisecond), nil
+		},
+	}
+	for name, tc := range cases {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "mdindexer")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			corpora := Corpora{NewCorpus(dir, Languages{"english"})}
+			n, err := tc(corpora)
+			if err != nil {
+				t.Skipf("notifier unavailable: %v", err)
+			}
+			index := New(4)
+			w := newWatcher(index, n, &Indexer{Corpora: corpora, Languages: Languages{"english"}}, 10*time.Millisecond)
+			defer w.Close()
+
+			filename := filepath.Join(dir, "sub", "hello.md")
+			err = os.Mkdir(filepath.Dir(filename), 0755)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ioutil.WriteFile(filename, []byte("hello world"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = ioutil.WriteFile(filepath.Join(dir, "ignored.txt"), []byte("hello"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eventually(t, func() bool {
+				return cmp.Equal(index.Documents(), []string{filename})
+			})
+
+			err = ioutil.WriteFile(filename, []byte("ciao world!"), 0644)
+			if err != nil {
+				t.Fatal(err)
+			}
+			eventually(t, func() bool {
+				docs, _ := index.Search("ciao")
+				return len(docs) == 1 && docs[0].Distance == 0
+			})
+
+			err = os.RemoveAll(filepath.Dir(filename))
+			if err != nil {
+				t.Fatal(err)
+			}
+			eventually(t, func() bool {
+				return len(index.Documents()) == 0
+			})
+		})
+	}
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met before deadline")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func Test_watcher_follows_symlinked_dirs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	target := filepath.Join(dir, "target")
+	root := filepath.Join(dir, "root")
+	for _, d := range []string{target, root} {
+		err = os.Mkdir(d, 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = os.Symlink(target, filepath.Join(root, "linked"))
+	if err != nil {
+		t.Skipf("symlinks unavailable: %v", err)
+	}
+
+	c := NewCorpus(root, Languages{"english"})
+	c.FollowSymlinks = true
+	corpora := Corpora{c}
+	n, err := newNotifier(corpora)
+	if err != nil {
+		t.Skipf("notifier unavailable: %v", err)
+	}
+	index := New(4)
+	w := newWatcher(index, n, &Indexer{Corpora: corpora, Languages: Languages{"english"}}, 10*time.Millisecond)
+	defer w.Close()
+
+	err = ioutil.WriteFile(filepath.Join(target, "hello.md"), []byte("hello world"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, func() bool {
+		return cmp.Equal(index.Documents(), []string{filepath.Join(root, "linked", "hello.md")})
+	})
+}
+
+type fakeNotifier chan string
+
+func (f fakeNotifier) Events() <-chan string { return f }
+
+func (f fakeNotifier) Close() error {
+	close(f)
+	return nil
+}
+
+func Test_watcher_resync_removes_missed_deletions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mdindexer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	kept := filepath.Join(dir, "kept.md")
+	gone := filepath.Join(dir, "gone.md")
+	for _, name := range []string{kept, gone} {
+		err = ioutil.WriteFile(name, []byte("hello world"), 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ix := &Indexer{Corpora: Corpora{NewCorpus(dir, Languages{"english"})}, Languages: Languages{"english"}, Workers: 1}
+	index := ix.Build()
+	err = os.Remove(gone)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := make(fakeNotifier, 1)
+	w := newWatcher(index, n, ix, time.Millisecond)
+	n <- resync
+	w.Close()
+
+	expected := []string{kept}
+	actual := index.Documents()
+	if !cmp.Equal(expected, actual) {
+		t.Errorf("Documents mismatch (-want +got)\n%s", cmp.Diff(expected, actual))
+	}
+}